@@ -0,0 +1,101 @@
+package audio
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"time"
+)
+
+type Format string
+
+const (
+	MP3  Format = "mp3"
+	Ogg  Format = "ogg"
+	M4A  Format = "m4a"
+	WebM Format = "webm"
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported audio format")
+	ErrInvalidAudio      = errors.New("invalid audio file")
+)
+
+var contentTypes = map[Format]string{
+	MP3:  "audio/mpeg",
+	Ogg:  "audio/ogg",
+	M4A:  "audio/mp4",
+	WebM: "audio/webm",
+}
+
+// Info describes an audio file. Duration is zero when the length of the file
+// can't be read from it.
+type Info struct {
+	Format   Format
+	Duration time.Duration
+}
+
+func (i Info) ContentType() string {
+	return contentTypes[i.Format]
+}
+
+func (i Info) Extension() string {
+	return "." + string(i.Format)
+}
+
+// Detect recognises the format from the first bytes of a file.
+func Detect(header []byte) (Format, bool) {
+	switch {
+	case bytes.HasPrefix(header, []byte("ID3")):
+		return MP3, true
+	case len(header) >= 2 && header[0] == 0xFF && header[1]&0xE0 == 0xE0:
+		return MP3, true
+	case bytes.HasPrefix(header, []byte("OggS")):
+		return Ogg, true
+	case len(header) >= 8 && string(header[4:8]) == "ftyp":
+		return M4A, true
+	case bytes.HasPrefix(header, []byte{0x1A, 0x45, 0xDF, 0xA3}):
+		return WebM, true
+	}
+	return "", false
+}
+
+// Probe detects the format of an audio file from its content and reads its
+// duration from the container. The file is left at an unspecified offset.
+func Probe(file io.ReadSeeker) (Info, error) {
+	header := make([]byte, 12)
+	n, err := io.ReadFull(file, header)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		if errors.Is(err, io.EOF) {
+			return Info{}, ErrInvalidAudio
+		}
+		return Info{}, err
+	}
+	format, ok := Detect(header[:n])
+	if !ok {
+		return Info{}, ErrUnsupportedFormat
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return Info{}, err
+	}
+
+	info := Info{Format: format}
+	switch format {
+	case MP3:
+		info.Duration, err = mp3Duration(file)
+	case Ogg:
+		info.Duration, err = oggDuration(file)
+	case M4A:
+		info.Duration, err = mp4Duration(file)
+	case WebM:
+		info.Duration, err = webmDuration(file)
+	}
+	if err != nil {
+		return Info{}, err
+	}
+	return info, nil
+}
+
+func seconds(value float64) time.Duration {
+	return time.Duration(value * float64(time.Second))
+}
@@ -0,0 +1,112 @@
+package audio
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"time"
+)
+
+// Bitrates in kbit/s by MPEG version (1, or 2 and 2.5) and layer (1-3).
+var (
+	mpeg1Bitrates = [3][15]int{
+		{0, 32, 64, 96, 128, 160, 192, 224, 256, 288, 320, 352, 384, 416, 448},
+		{0, 32, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 384},
+		{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320},
+	}
+	mpeg2Bitrates = [3][15]int{
+		{0, 32, 48, 56, 64, 80, 96, 112, 128, 144, 160, 176, 192, 224, 256},
+		{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+		{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+	}
+	// Sample rates by MPEG version 1, 2 and 2.5.
+	sampleRates = [3][3]int{
+		{44100, 48000, 32000},
+		{22050, 24000, 16000},
+		{11025, 12000, 8000},
+	}
+)
+
+type mp3Frame struct {
+	length  int
+	samples int
+	rate    int
+}
+
+func parseMP3Frame(header []byte) (mp3Frame, bool) {
+	if header[0] != 0xFF || header[1]&0xE0 != 0xE0 {
+		return mp3Frame{}, false
+	}
+	versionBits := header[1] >> 3 & 0x03
+	layerBits := header[1] >> 1 & 0x03
+	bitrateIndex := header[2] >> 4
+	rateIndex := header[2] >> 2 & 0x03
+	padding := int(header[2] >> 1 & 0x01)
+	if versionBits == 1 || layerBits == 0 || bitrateIndex == 0 || bitrateIndex == 15 || rateIndex == 3 {
+		return mp3Frame{}, false
+	}
+
+	version := map[byte]int{3: 0, 2: 1, 0: 2}[versionBits]
+	layer := int(3 - layerBits) // 0 is layer I
+	bitrate := mpeg1Bitrates[layer][bitrateIndex]
+	if version > 0 {
+		bitrate = mpeg2Bitrates[layer][bitrateIndex]
+	}
+	bitrate *= 1000
+	rate := sampleRates[version][rateIndex]
+
+	switch {
+	case layer == 0:
+		return mp3Frame{length: (12*bitrate/rate + padding) * 4, samples: 384, rate: rate}, true
+	case layer == 2 && version > 0:
+		return mp3Frame{length: 72*bitrate/rate + padding, samples: 576, rate: rate}, true
+	default:
+		return mp3Frame{length: 144*bitrate/rate + padding, samples: 1152, rate: rate}, true
+	}
+}
+
+// mp3Duration adds up the length of every MPEG audio frame, which works for
+// constant and variable bitrates alike.
+func mp3Duration(file io.Reader) (time.Duration, error) {
+	reader := bufio.NewReader(file)
+
+	// Skip an ID3v2 tag.
+	if tag, err := reader.Peek(10); err == nil && string(tag[:3]) == "ID3" {
+		size := int(tag[6])<<21 | int(tag[7])<<14 | int(tag[8])<<7 | int(tag[9])
+		if tag[5]&0x10 != 0 {
+			size += 10 // footer
+		}
+		if _, err := reader.Discard(10 + size); err != nil {
+			return 0, ErrInvalidAudio
+		}
+	}
+
+	var total float64
+	frames := 0
+	for {
+		header, err := reader.Peek(4)
+		if err != nil {
+			break
+		}
+		if string(header[:3]) == "TAG" {
+			break // ID3v1 tag at the end
+		}
+		frame, ok := parseMP3Frame(header)
+		if !ok {
+			// Skip junk between frames.
+			if _, err := reader.Discard(1); err != nil {
+				break
+			}
+			continue
+		}
+		if _, err := reader.Discard(frame.length); err != nil && !errors.Is(err, io.EOF) {
+			return 0, err
+		}
+		frames++
+		total += float64(frame.samples) / float64(frame.rate)
+	}
+	if frames == 0 {
+		return 0, ErrInvalidAudio
+	}
+	return seconds(total), nil
+}
@@ -0,0 +1,80 @@
+package audio
+
+import (
+	"encoding/binary"
+	"io"
+	"time"
+)
+
+// box reads the header of an ISO base media (MP4) box and returns its type
+// and the size of its content, -1 when it runs to the end of the file.
+func box(file io.Reader) (string, int64, error) {
+	header := make([]byte, 8)
+	if _, err := io.ReadFull(file, header); err != nil {
+		return "", 0, err
+	}
+	size := int64(binary.BigEndian.Uint32(header[:4]))
+	kind := string(header[4:8])
+	switch size {
+	case 0:
+		return kind, -1, nil
+	case 1:
+		large := make([]byte, 8)
+		if _, err := io.ReadFull(file, large); err != nil {
+			return "", 0, err
+		}
+		size = int64(binary.BigEndian.Uint64(large)) - 16
+	default:
+		size -= 8
+	}
+	if size < 0 {
+		return "", 0, ErrInvalidAudio
+	}
+	return kind, size, nil
+}
+
+// mp4Duration reads the duration from the movie header (moov/mvhd).
+func mp4Duration(file io.ReadSeeker) (time.Duration, error) {
+	// Find moov among the top level boxes, then mvhd inside it.
+	for _, wanted := range []string{"moov", "mvhd"} {
+		for {
+			kind, size, err := box(file)
+			if err != nil {
+				return 0, ErrInvalidAudio
+			}
+			if kind == wanted {
+				break
+			}
+			if size < 0 {
+				return 0, ErrInvalidAudio
+			}
+			if _, err := file.Seek(size, io.SeekCurrent); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	header := make([]byte, 32)
+	if _, err := io.ReadFull(file, header[:4]); err != nil {
+		return 0, ErrInvalidAudio
+	}
+	var timescale, duration uint64
+	if header[0] == 1 {
+		// Version 1: 64-bit creation and modification times and duration.
+		if _, err := io.ReadFull(file, header[4:32]); err != nil {
+			return 0, ErrInvalidAudio
+		}
+		timescale = uint64(binary.BigEndian.Uint32(header[20:24]))
+		duration = binary.BigEndian.Uint64(header[24:32])
+	} else {
+		if _, err := io.ReadFull(file, header[4:20]); err != nil {
+			return 0, ErrInvalidAudio
+		}
+		timescale = uint64(binary.BigEndian.Uint32(header[12:16]))
+		duration = uint64(binary.BigEndian.Uint32(header[16:20]))
+	}
+	if timescale == 0 {
+		return 0, ErrInvalidAudio
+	}
+	return seconds(float64(duration) / float64(timescale)), nil
+}
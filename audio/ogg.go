@@ -0,0 +1,67 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"time"
+)
+
+// maxOggPage is the largest possible Ogg page: a 27 byte header, 255
+// segment sizes and 255 segments of 255 bytes.
+const maxOggPage = 27 + 255 + 255*255
+
+// oggDuration divides the granule position of the last page by the sample
+// rate from the identification header of the first Vorbis or Opus stream.
+func oggDuration(file io.ReadSeeker) (time.Duration, error) {
+	first := make([]byte, 27+255+64)
+	n, err := io.ReadFull(file, first)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return 0, ErrInvalidAudio
+	}
+	first = first[:n]
+	if len(first) < 27 || int(first[26])+27 > len(first) {
+		return 0, ErrInvalidAudio
+	}
+	packet := first[27+int(first[26]):]
+
+	var rate, preSkip int64
+	switch {
+	case bytes.HasPrefix(packet, []byte("\x01vorbis")) && len(packet) >= 16:
+		rate = int64(binary.LittleEndian.Uint32(packet[12:16]))
+	case bytes.HasPrefix(packet, []byte("OpusHead")) && len(packet) >= 12:
+		// Opus granule positions always count 48 kHz samples.
+		rate = 48000
+		preSkip = int64(binary.LittleEndian.Uint16(packet[10:12]))
+	default:
+		return 0, ErrUnsupportedFormat
+	}
+	if rate == 0 {
+		return 0, ErrInvalidAudio
+	}
+
+	size, err := file.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, err
+	}
+	start := size - maxOggPage
+	if start < 0 {
+		start = 0
+	}
+	if _, err := file.Seek(start, io.SeekStart); err != nil {
+		return 0, err
+	}
+	tail, err := io.ReadAll(file)
+	if err != nil {
+		return 0, err
+	}
+	last := bytes.LastIndex(tail, []byte("OggS"))
+	if last < 0 || last+14 > len(tail) {
+		return 0, ErrInvalidAudio
+	}
+	granule := int64(binary.LittleEndian.Uint64(tail[last+6 : last+14]))
+	if granule < preSkip {
+		return 0, ErrInvalidAudio
+	}
+	return seconds(float64(granule-preSkip) / float64(rate)), nil
+}
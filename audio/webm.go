@@ -0,0 +1,182 @@
+package audio
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"time"
+)
+
+// Matroska element IDs.
+const (
+	ebmlSegment       = 0x18538067
+	ebmlInfo          = 0x1549A966
+	ebmlCluster       = 0x1F43B675
+	ebmlBlockGroup    = 0xA0
+	ebmlTimecodeScale = 0x2AD7B1
+	ebmlDuration      = 0x4489
+	ebmlTimecode      = 0xE7
+	ebmlSimpleBlock   = 0xA3
+	ebmlBlock         = 0xA1
+)
+
+// readVint reads an EBML variable length integer. IDs keep their length
+// marker; sizes don't, and a size of all ones means unknown (-1).
+func readVint(file io.Reader, keepMarker bool) (int64, error) {
+	first := make([]byte, 1)
+	if _, err := io.ReadFull(file, first); err != nil {
+		return 0, err
+	}
+	length := 1
+	for mask := byte(0x80); length <= 8 && first[0]&mask == 0; mask >>= 1 {
+		length++
+	}
+	if length > 8 {
+		return 0, ErrInvalidAudio
+	}
+
+	value := int64(first[0])
+	if !keepMarker {
+		value &= int64(0xFF >> length)
+	}
+	rest := make([]byte, length-1)
+	if _, err := io.ReadFull(file, rest); err != nil {
+		return 0, err
+	}
+	allOnes := value == int64(0xFF>>length)
+	for _, b := range rest {
+		value = value<<8 | int64(b)
+		allOnes = allOnes && b == 0xFF
+	}
+	if !keepMarker && allOnes {
+		return -1, nil
+	}
+	return value, nil
+}
+
+func element(file io.Reader) (int64, int64, error) {
+	id, err := readVint(file, true)
+	if err != nil {
+		return 0, 0, err
+	}
+	size, err := readVint(file, false)
+	if err != nil {
+		return 0, 0, err
+	}
+	return id, size, nil
+}
+
+// webmDuration reads Segment/Info/Duration, which is counted in units of
+// TimecodeScale nanoseconds. Recordings streamed by a browser often don't
+// have it; their duration is then where the last block starts, from the
+// timecodes of the clusters and of the blocks in them. It is zero when the
+// file has neither.
+func webmDuration(file io.ReadSeeker) (time.Duration, error) {
+	// Skip the EBML header.
+	_, size, err := element(file)
+	if err != nil || size < 0 {
+		return 0, ErrInvalidAudio
+	}
+	if _, err := file.Seek(size, io.SeekCurrent); err != nil {
+		return 0, err
+	}
+
+	id, _, err := element(file)
+	if err != nil || id != ebmlSegment {
+		return 0, ErrInvalidAudio
+	}
+
+	// Walk the elements in file order, looking inside the ones that hold
+	// what's needed. Streamed files don't give the size of the segment or
+	// its clusters, so the walk goes on to the end of the file.
+	scale := 1000000.0
+	duration := 0.0
+	var cluster, last int64
+	for {
+		id, size, err := element(file)
+		if err != nil {
+			break
+		}
+		if id == ebmlCluster && duration > 0 {
+			break
+		}
+
+		switch id {
+		case ebmlInfo, ebmlCluster, ebmlBlockGroup:
+			continue
+		case ebmlTimecodeScale, ebmlTimecode:
+			value, err := readUint(file, size)
+			if err != nil {
+				return 0, err
+			}
+			if id == ebmlTimecodeScale {
+				scale = float64(value)
+			} else {
+				cluster = int64(value)
+			}
+		case ebmlDuration:
+			// The size comes from the upload, so it is checked before
+			// anything is read.
+			if size != 4 && size != 8 {
+				return 0, ErrInvalidAudio
+			}
+			var value [8]byte
+			if _, err := io.ReadFull(file, value[:size]); err != nil {
+				return 0, ErrInvalidAudio
+			}
+			if size == 4 {
+				duration = float64(math.Float32frombits(binary.BigEndian.Uint32(value[:4])))
+			} else {
+				duration = math.Float64frombits(binary.BigEndian.Uint64(value[:]))
+			}
+		case ebmlSimpleBlock, ebmlBlock:
+			// A block starts with its track number and its timecode
+			// relative to the cluster.
+			if size < 3 {
+				return 0, ErrInvalidAudio
+			}
+			start, _ := file.Seek(0, io.SeekCurrent)
+			if _, err := readVint(file, false); err != nil {
+				return 0, ErrInvalidAudio
+			}
+			var relative int16
+			if err := binary.Read(file, binary.BigEndian, &relative); err != nil {
+				return 0, ErrInvalidAudio
+			}
+			if at := cluster + int64(relative); at > last {
+				last = at
+			}
+			if _, err := file.Seek(start+size, io.SeekStart); err != nil {
+				return 0, err
+			}
+		default:
+			if size < 0 {
+				return 0, ErrInvalidAudio
+			}
+			if _, err := file.Seek(size, io.SeekCurrent); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	if duration == 0 {
+		duration = float64(last)
+	}
+	return time.Duration(duration * scale), nil
+}
+
+// readUint reads an unsigned integer element of size bytes.
+func readUint(file io.Reader, size int64) (uint64, error) {
+	if size < 0 || size > 8 {
+		return 0, ErrInvalidAudio
+	}
+	var value [8]byte
+	if _, err := io.ReadFull(file, value[:size]); err != nil {
+		return 0, ErrInvalidAudio
+	}
+	unsigned := uint64(0)
+	for _, b := range value[:size] {
+		unsigned = unsigned<<8 | uint64(b)
+	}
+	return unsigned, nil
+}
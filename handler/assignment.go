@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"a21hc3NpZ25tZW50/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func registerAssignmentRoutes(protected *gin.RouterGroup, svc *service.Service) {
+	protected.POST("/groups", func(c *gin.Context) {
+		var request struct {
+			Name string `json:"name"`
+		}
+		if !bindJSON(c, &request) {
+			return
+		}
+
+		teacher, ok := currentUser(c, svc)
+		if !ok {
+			return
+		}
+
+		group, err := svc.CreateGroup(teacher, request.Name)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusCreated, group)
+	})
+
+	protected.GET("/groups", func(c *gin.Context) {
+		teacher, ok := currentUser(c, svc)
+		if !ok {
+			return
+		}
+
+		groups, err := svc.GetGroups(teacher)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, groups)
+	})
+
+	protected.POST("/groups/:id/members", func(c *gin.Context) {
+		groupID, ok := parseIDParam(c, "id")
+		if !ok {
+			return
+		}
+		var request struct {
+			Username string `json:"username"`
+		}
+		if !bindJSON(c, &request) {
+			return
+		}
+
+		teacher, ok := currentUser(c, svc)
+		if !ok {
+			return
+		}
+
+		group, err := svc.AddGroupMember(teacher, groupID, request.Username)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, group)
+	})
+
+	protected.DELETE("/groups/:id/members/:userId", func(c *gin.Context) {
+		groupID, ok := parseIDParam(c, "id")
+		if !ok {
+			return
+		}
+		userID, ok := parseIDParam(c, "userId")
+		if !ok {
+			return
+		}
+
+		teacher, ok := currentUser(c, svc)
+		if !ok {
+			return
+		}
+
+		if err := svc.RemoveGroupMember(teacher, groupID, userID); err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "Group member removed"})
+	})
+
+	protected.POST("/assignments", func(c *gin.Context) {
+		var input service.AssignmentInput
+		if !bindJSON(c, &input) {
+			return
+		}
+
+		teacher, ok := currentUser(c, svc)
+		if !ok {
+			return
+		}
+
+		assignment, err := svc.CreateAssignment(teacher, input)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusCreated, assignment)
+	})
+
+	protected.GET("/assignments", func(c *gin.Context) {
+		user, ok := currentUser(c, svc)
+		if !ok {
+			return
+		}
+
+		assignments, err := svc.GetAssignments(user)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, assignments)
+	})
+
+	protected.GET("/assignments/:id", func(c *gin.Context) {
+		assignmentID, ok := parseIDParam(c, "id")
+		if !ok {
+			return
+		}
+
+		user, ok := currentUser(c, svc)
+		if !ok {
+			return
+		}
+
+		assignment, err := svc.GetAssignment(user, assignmentID)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, assignment)
+	})
+}
@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"a21hc3NpZ25tZW50/service"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func registerCommentRoutes(protected *gin.RouterGroup, svc *service.Service) {
+	protected.POST("/memorizes/:id/comments", func(c *gin.Context) {
+		memorizeID, ok := parseIDParam(c, "id")
+		if !ok {
+			return
+		}
+
+		var input service.CommentInput
+		if !bindJSON(c, &input) {
+			return
+		}
+
+		user, ok := currentUser(c, svc)
+		if !ok {
+			return
+		}
+
+		comment, err := svc.AddComment(user, memorizeID, input)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusCreated, comment)
+	})
+
+	// Reading the comments marks them as read.
+	protected.GET("/memorizes/:id/comments", func(c *gin.Context) {
+		memorizeID, ok := parseIDParam(c, "id")
+		if !ok {
+			return
+		}
+
+		var recordingID *uint
+		if value := c.Query("recordingId"); value != "" {
+			id, err := strconv.ParseUint(value, 10, 0)
+			if err != nil {
+				c.Error(badRequest("Invalid recordingId"))
+				return
+			}
+			recording := uint(id)
+			recordingID = &recording
+		}
+
+		user, ok := currentUser(c, svc)
+		if !ok {
+			return
+		}
+
+		thread, err := svc.GetComments(user, memorizeID, recordingID, time.Now())
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, thread)
+	})
+
+	protected.PUT("/comments/:id", func(c *gin.Context) {
+		commentID, ok := parseIDParam(c, "id")
+		if !ok {
+			return
+		}
+
+		var input struct {
+			Body string
+		}
+		if !bindJSON(c, &input) {
+			return
+		}
+
+		user, ok := currentUser(c, svc)
+		if !ok {
+			return
+		}
+
+		comment, err := svc.UpdateComment(user, commentID, input.Body, time.Now())
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, comment)
+	})
+
+	protected.DELETE("/comments/:id", func(c *gin.Context) {
+		commentID, ok := parseIDParam(c, "id")
+		if !ok {
+			return
+		}
+
+		user, ok := currentUser(c, svc)
+		if !ok {
+			return
+		}
+
+		if err := svc.DeleteComment(user, commentID); err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "Comment deleted"})
+	})
+
+	protected.GET("/me/comments/unread", func(c *gin.Context) {
+		user, ok := currentUser(c, svc)
+		if !ok {
+			return
+		}
+
+		unread, err := svc.GetUnreadComments(user)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, unread)
+	})
+}
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OpenAPISpec is the OpenAPI 3 document of the API, served at /openapi.json.
+// Every route registered by NewRouter must be in it.
+//
+//go:embed openapi.json
+var OpenAPISpec []byte
+
+// docsAssets is the dist of Swagger UI 5.18.2 (Apache License 2.0, see
+// docs/LICENSE), so the docs page works without reaching a CDN. To update
+// it, copy swagger-ui-bundle.js and swagger-ui.css from a newer dist.
+//
+//go:embed docs/swagger-ui-bundle.js docs/swagger-ui.css
+var docsAssets embed.FS
+
+// docsPage renders OpenAPISpec with Swagger UI.
+const docsPage = `<!DOCTYPE html>
+<html>
+<head>
+  <title>Memorization Tracker API</title>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <link rel="stylesheet" href="/docs/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="/docs/swagger-ui-bundle.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({url: "/openapi.json", dom_id: "#swagger-ui", deepLinking: true});
+  </script>
+</body>
+</html>
+`
+
+// registerDocsRoutes serves the API documentation. It doesn't need a login.
+func registerDocsRoutes(router *gin.Engine) {
+	router.GET("/openapi.json", func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json", OpenAPISpec)
+	})
+
+	router.GET("/docs", func(c *gin.Context) {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(docsPage))
+	})
+
+	assets, _ := fs.Sub(docsAssets, "docs")
+	router.GET("/docs/:file", func(c *gin.Context) {
+		file := c.Param("file")
+		if _, err := fs.Stat(assets, file); err != nil {
+			c.Error(&requestError{http.StatusNotFound, CodeNotFound, "Page not found"})
+			return
+		}
+		c.FileFromFS(file, http.FS(assets))
+	})
+}
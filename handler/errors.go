@@ -0,0 +1,232 @@
+package handler
+
+import (
+	"a21hc3NpZ25tZW50/audio"
+	"a21hc3NpZ25tZW50/model"
+	"a21hc3NpZ25tZW50/quran"
+	"a21hc3NpZ25tZW50/review"
+	"a21hc3NpZ25tZW50/service"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// Codes in error responses, for clients to tell errors apart without
+// parsing the message.
+const (
+	CodeInvalidRequest       = "invalid_request"
+	CodeValidationFailed     = "validation_failed"
+	CodeUnauthorized         = "unauthorized"
+	CodeForbidden            = "forbidden"
+	CodeNotFound             = "not_found"
+	CodeConflict             = "conflict"
+	CodePayloadTooLarge      = "payload_too_large"
+	CodeUnsupportedMediaType = "unsupported_media_type"
+	CodeInternal             = "internal_error"
+)
+
+// ErrorResponse is the body of every error response.
+type ErrorResponse struct {
+	Error ErrorBody `json:"error"`
+}
+
+type ErrorBody struct {
+	Code    string       `json:"code"`
+	Message string       `json:"message"`
+	Details []FieldError `json:"details,omitempty"`
+}
+
+// FieldError says what is wrong with one field of the request.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// requestError is a problem with the request found by the handler itself,
+// before it gets to the service.
+type requestError struct {
+	status  int
+	code    string
+	message string
+}
+
+func (e *requestError) Error() string { return e.message }
+
+func badRequest(message string) error {
+	return &requestError{http.StatusBadRequest, CodeInvalidRequest, message}
+}
+
+func unauthorized(message string) error {
+	return &requestError{http.StatusUnauthorized, CodeUnauthorized, message}
+}
+
+// bindError is a request body that couldn't be decoded or failed its
+// binding rules.
+type bindError struct {
+	err error
+}
+
+func (e *bindError) Error() string { return e.err.Error() }
+
+func (e *bindError) Unwrap() error { return e.err }
+
+// bindJSON decodes the JSON body of a request into v. It adds the error to
+// the context and returns false when that fails.
+func bindJSON(c *gin.Context, v interface{}) bool {
+	if err := c.ShouldBindJSON(v); err != nil {
+		c.Error(&bindError{err})
+		return false
+	}
+	return true
+}
+
+// ErrorMiddleware answers a request whose handler failed with c.Error,
+// unless the handler already wrote a response.
+func ErrorMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		err := c.Errors.Last().Err
+		status, body := errorResponse(err)
+		if status == http.StatusInternalServerError {
+			requestLogger(c).Error("request failed", "method", c.Request.Method, "path", c.Request.URL.Path, "err", err)
+		}
+		c.JSON(status, ErrorResponse{Error: body})
+	}
+}
+
+// errorResponse maps an error to a status code and response body. The
+// details of unexpected errors are only logged.
+func errorResponse(err error) (int, ErrorBody) {
+	body := ErrorBody{Message: err.Error()}
+
+	var reqErr *requestError
+	var bindErr *bindError
+	switch {
+	case errors.As(err, &reqErr):
+		body.Code = reqErr.code
+		return reqErr.status, body
+	case errors.As(err, &bindErr):
+		return http.StatusBadRequest, bindErrorBody(bindErr.err)
+	case errors.Is(err, service.ErrValidation), errors.Is(err, quran.ErrInvalidAyahRange),
+		errors.Is(err, audio.ErrInvalidAudio):
+		body.Code = CodeValidationFailed
+		return http.StatusBadRequest, body
+	case errors.Is(err, service.ErrInvalidCredentials):
+		body.Code = CodeUnauthorized
+		return http.StatusUnauthorized, body
+	case errors.Is(err, service.ErrForbidden):
+		body.Code = CodeForbidden
+		return http.StatusForbidden, body
+	case errors.Is(err, service.ErrNotFound), errors.Is(err, quran.ErrTextUnavailable):
+		body.Code = CodeNotFound
+		return http.StatusNotFound, body
+	case errors.Is(err, service.ErrConflict):
+		body.Code = CodeConflict
+		return http.StatusConflict, body
+	case errors.Is(err, service.ErrRecordingTooLarge):
+		body.Code = CodePayloadTooLarge
+		return http.StatusRequestEntityTooLarge, body
+	case errors.Is(err, audio.ErrUnsupportedFormat):
+		body.Code = CodeUnsupportedMediaType
+		return http.StatusUnsupportedMediaType, body
+	default:
+		return http.StatusInternalServerError, ErrorBody{Code: CodeInternal, Message: "internal server error"}
+	}
+}
+
+// bindErrorBody reports each field of a request body that has the wrong type
+// or breaks a binding rule.
+func bindErrorBody(err error) ErrorBody {
+	var typeErr *json.UnmarshalTypeError
+	var validationErrs validator.ValidationErrors
+	switch {
+	case errors.As(err, &typeErr):
+		return ErrorBody{
+			Code:    CodeInvalidRequest,
+			Message: "request body has a field of the wrong type",
+			Details: []FieldError{{Field: typeErr.Field, Message: "must be " + jsonTypeName(typeErr.Type)}},
+		}
+	case errors.As(err, &validationErrs):
+		body := ErrorBody{Code: CodeValidationFailed, Message: "request body is invalid"}
+		for _, fieldErr := range validationErrs {
+			body.Details = append(body.Details, FieldError{Field: fieldErr.Field(), Message: ruleMessage(fieldErr)})
+		}
+		return body
+	default:
+		return ErrorBody{Code: CodeInvalidRequest, Message: "request body must be valid JSON: " + err.Error()}
+	}
+}
+
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.String:
+		return "a string"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.Ptr:
+		return jsonTypeName(t.Elem())
+	default:
+		return "an object"
+	}
+}
+
+// ruleMessage describes the binding rule a field broke.
+func ruleMessage(fieldErr validator.FieldError) string {
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "min":
+		return fmt.Sprintf("must be at least %s", fieldErr.Param())
+	case "max":
+		return fmt.Sprintf("must be at most %s", fieldErr.Param())
+	case "oneof":
+		return fmt.Sprintf("must be one of %s", fieldErr.Param())
+	case "required_without":
+		return fmt.Sprintf("is required without %s", fieldErr.Param())
+	case "required_with":
+		return fmt.Sprintf("is required with %s", fieldErr.Param())
+	case "gtefield":
+		return fmt.Sprintf("must not be before %s", fieldErr.Param())
+	case "url":
+		return "must be a URL"
+	case "username":
+		return "must be 3 to 30 letters, digits, '_' or '.'"
+	case "timezone":
+		return "must be an IANA timezone such as Asia/Jakarta"
+	case "surah":
+		return "must be the name or number of a surah"
+	case "ayah_range":
+		return "must be an ayah or a range of ayahs such as 1-7"
+	case "within_surah":
+		return fmt.Sprintf("must be within the %s ayahs of the surah", fieldErr.Param())
+	case "page_range":
+		return fmt.Sprintf("must be a page or a range of pages between 1 and %d", quran.PageCount)
+	case "accuracy":
+		return model.ErrInvalidAccuracy.Error()
+	case "review_frequency":
+		// The parser says best what is wrong with it.
+		if value, ok := fieldErr.Value().(string); ok {
+			if _, err := review.ParseFrequency(value); err != nil {
+				return err.Error()
+			}
+		}
+		return "must be a review frequency such as weekly or every:3"
+	default:
+		return fmt.Sprintf("failed the %s rule", fieldErr.Tag())
+	}
+}
@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"a21hc3NpZ25tZW50/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func registerEvaluationRoutes(protected *gin.RouterGroup, svc *service.Service) {
+	protected.POST("/evaluations", func(c *gin.Context) {
+		var input service.EvaluationInput
+		if !bindJSON(c, &input) {
+			return
+		}
+
+		teacher, ok := currentUser(c, svc)
+		if !ok {
+			return
+		}
+
+		evaluation, err := svc.CreateEvaluation(teacher, input)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusCreated, evaluation)
+	})
+
+	protected.GET("/evaluations", func(c *gin.Context) {
+		user, ok := currentUser(c, svc)
+		if !ok {
+			return
+		}
+
+		evaluations, err := svc.GetEvaluations(user)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, evaluations)
+	})
+
+	protected.GET("/evaluations/:id", func(c *gin.Context) {
+		evaluationID, ok := parseIDParam(c, "id")
+		if !ok {
+			return
+		}
+
+		user, ok := currentUser(c, svc)
+		if !ok {
+			return
+		}
+
+		evaluation, err := svc.GetEvaluation(user, evaluationID)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, evaluation)
+	})
+
+	protected.GET("/students/:username/evaluations", func(c *gin.Context) {
+		user, ok := currentUser(c, svc)
+		if !ok {
+			return
+		}
+
+		evaluations, err := svc.GetStudentEvaluations(user, c.Param("username"))
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, evaluations)
+	})
+
+	protected.GET("/students/:username/weak-ayahs", func(c *gin.Context) {
+		user, ok := currentUser(c, svc)
+		if !ok {
+			return
+		}
+
+		weakAyahs, err := svc.GetWeakAyahs(user, c.Param("username"))
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, weakAyahs)
+	})
+}
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"a21hc3NpZ25tZW50/service"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func registerGoalRoutes(protected *gin.RouterGroup, svc *service.Service) {
+	protected.POST("/goals", func(c *gin.Context) {
+		var input service.GoalInput
+		if !bindJSON(c, &input) {
+			return
+		}
+
+		user, ok := currentUser(c, svc)
+		if !ok {
+			return
+		}
+
+		goal, err := svc.CreateGoal(user, input, time.Now())
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusCreated, goal)
+	})
+
+	protected.GET("/goals", func(c *gin.Context) {
+		user, ok := currentUser(c, svc)
+		if !ok {
+			return
+		}
+
+		goals, err := svc.GetGoals(user, time.Now())
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, goals)
+	})
+
+	protected.GET("/goals/:id", func(c *gin.Context) {
+		goalID, ok := parseIDParam(c, "id")
+		if !ok {
+			return
+		}
+
+		user, ok := currentUser(c, svc)
+		if !ok {
+			return
+		}
+
+		progress, err := svc.GetGoal(user, goalID, time.Now())
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, progress)
+	})
+
+	protected.GET("/goals/:id/plan", func(c *gin.Context) {
+		goalID, ok := parseIDParam(c, "id")
+		if !ok {
+			return
+		}
+
+		user, ok := currentUser(c, svc)
+		if !ok {
+			return
+		}
+
+		plan, err := svc.GetGoalPlan(user, goalID, time.Now())
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, plan)
+	})
+
+	protected.DELETE("/goals/:id", func(c *gin.Context) {
+		goalID, ok := parseIDParam(c, "id")
+		if !ok {
+			return
+		}
+
+		user, ok := currentUser(c, svc)
+		if !ok {
+			return
+		}
+
+		if err := svc.DeleteGoal(user, goalID); err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "Goal deleted"})
+	})
+}
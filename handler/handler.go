@@ -0,0 +1,123 @@
+// Package handler serves the API over HTTP. Handlers only parse requests and
+// write responses; everything else is left to the service package.
+package handler
+
+import (
+	"a21hc3NpZ25tZW50/model"
+	"a21hc3NpZ25tZW50/service"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+)
+
+// The routes were served at the root before /api/v1. They still are, but
+// responses there say when they will be removed.
+var (
+	legacyDeprecatedAt = time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+	legacySunsetAt     = time.Date(2027, time.April, 30, 0, 0, 0, 0, time.UTC)
+)
+
+func NewRouter(svc *service.Service) *gin.Engine {
+	registerValidators()
+	router := gin.New()
+	router.Use(RequestIDMiddleware(), LoggerMiddleware(), MetricsMiddleware())
+
+	// Enable CORS for all origins, methods, and headers
+	router.Use(cors.New(cors.Config{
+		AllowOrigins:     []string{"http://localhost:3000"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", RequestIDHeader},
+		ExposeHeaders:    []string{"Deprecation", "Sunset", "Link", RequestIDHeader},
+		AllowCredentials: true,
+	}))
+	router.Use(ErrorMiddleware())
+	// A panic becomes a 500 response from ErrorMiddleware.
+	router.Use(gin.CustomRecovery(func(c *gin.Context, recovered interface{}) {
+		c.Error(fmt.Errorf("panic: %v", recovered))
+		c.Abort()
+	}))
+
+	router.GET("/health", func(c *gin.Context) {
+		c.String(http.StatusOK, "OK")
+	})
+	registerDocsRoutes(router)
+	registerLogRoutes(router)
+	registerMetricsRoutes(router)
+
+	// Each version of the API is a group with its own routes, so a new
+	// version can change request and response bodies without affecting the
+	// clients of an older one.
+	registerV1Routes(router.Group("/api/v1"), svc)
+	registerV1Routes(router.Group("/", DeprecationMiddleware(legacyDeprecatedAt, legacySunsetAt, "/api/v1")), svc)
+
+	router.NoRoute(func(c *gin.Context) {
+		c.Error(&requestError{http.StatusNotFound, CodeNotFound, "Page not found"})
+	})
+
+	return router
+}
+
+func registerV1Routes(api *gin.RouterGroup, svc *service.Service) {
+	registerQuranRoutes(api)
+	registerUserRoutes(api, svc)
+
+	protected := api.Group("/")
+	protected.Use(AuthMiddleware())
+	{
+		registerMemorizeRoutes(protected, svc)
+		registerAssignmentRoutes(protected, svc)
+		registerEvaluationRoutes(protected, svc)
+		registerStatsRoutes(protected, svc)
+		registerGoalRoutes(protected, svc)
+		registerRotationRoutes(protected, svc)
+		registerQuizRoutes(protected, svc)
+		registerRecitationRoutes(protected, svc)
+		registerRecordingRoutes(protected, svc)
+		registerCommentRoutes(protected, svc)
+		registerReadingRoutes(protected, svc)
+	}
+}
+
+// DeprecationMiddleware marks the responses of deprecated routes with the
+// Deprecation (RFC 9745) and Sunset (RFC 8594) headers, and links to the
+// same route under successorPrefix.
+func DeprecationMiddleware(deprecatedAt, sunsetAt time.Time, successorPrefix string) gin.HandlerFunc {
+	deprecation := fmt.Sprintf("@%d", deprecatedAt.Unix())
+	sunset := sunsetAt.UTC().Format(http.TimeFormat)
+	return func(c *gin.Context) {
+		c.Header("Deprecation", deprecation)
+		c.Header("Sunset", sunset)
+		c.Header("Link", fmt.Sprintf(`<%s%s>; rel="successor-version"`, successorPrefix, c.Request.URL.Path))
+		c.Next()
+	}
+}
+
+// currentUser loads the user behind the JWT of an authenticated request. It
+// adds the error to the context and returns false when that fails.
+func currentUser(c *gin.Context, svc *service.Service) (model.User, bool) {
+	user, err := svc.GetUser(c.GetString("username"))
+	if err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			err = unauthorized("Invalid token claims")
+		}
+		c.Error(err)
+		return model.User{}, false
+	}
+	return user, true
+}
+
+// parseIDParam reads a numeric path parameter, failing with 400 when it is
+// malformed.
+func parseIDParam(c *gin.Context, name string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 64)
+	if err != nil || id == 0 {
+		c.Error(badRequest("Invalid " + name))
+		return 0, false
+	}
+	return uint(id), true
+}
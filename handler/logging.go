@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"a21hc3NpZ25tZW50/logging"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader carries the ID of a request, from the client or a proxy in
+// front of the server, or generated when there is none.
+const RequestIDHeader = "X-Request-ID"
+
+// LogAdminToken is the bearer token for changing the log level at runtime.
+// The level can't be changed when it is empty.
+var LogAdminToken string
+
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+const loggerKey = "logger"
+
+// RequestIDMiddleware gives each request an ID, echoed in the response, and
+// a logger that adds the ID to everything logged about the request.
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// An ID from outside ends up in the logs, so it must be safe there.
+		id := c.GetHeader(RequestIDHeader)
+		if !requestIDPattern.MatchString(id) {
+			id = newRequestID()
+		}
+		c.Header(RequestIDHeader, id)
+		c.Set(loggerKey, slog.Default().With("request_id", id))
+		c.Next()
+	}
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+// requestLogger is the logger for the request, with its ID.
+func requestLogger(c *gin.Context) *slog.Logger {
+	if logger, ok := c.Get(loggerKey); ok {
+		return logger.(*slog.Logger)
+	}
+	return slog.Default()
+}
+
+// LoggerMiddleware logs every request once it has been answered. Server
+// errors are logged at error level and client errors at warn level.
+func LoggerMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+		requestLogger(c).LogAttrs(c.Request.Context(), level, "request",
+			slog.String("method", c.Request.Method),
+			slog.String("route", c.FullPath()),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.Int("bytes", c.Writer.Size()),
+			slog.String("client_ip", c.ClientIP()),
+			slog.String("user", c.GetString("username")),
+		)
+	}
+}
+
+// registerLogRoutes lets an operator read and change the log level while the
+// server is running, with LogAdminToken.
+func registerLogRoutes(router *gin.Engine) {
+	admin := router.Group("/log-level", func(c *gin.Context) {
+		token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if LogAdminToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(LogAdminToken)) != 1 {
+			c.Error(&requestError{http.StatusForbidden, CodeForbidden, "a valid log admin token is required"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	})
+
+	admin.GET("", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"level": logging.LevelName()})
+	})
+
+	admin.PUT("", func(c *gin.Context) {
+		var request struct {
+			Level string `json:"level" binding:"required"`
+		}
+		if !bindJSON(c, &request) {
+			return
+		}
+		if err := logging.SetLevel(request.Level); err != nil {
+			c.Error(badRequest(err.Error()))
+			return
+		}
+		requestLogger(c).Info("log level changed", "level", logging.LevelName())
+		c.JSON(http.StatusOK, gin.H{"level": logging.LevelName()})
+	})
+}
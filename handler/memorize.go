@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"a21hc3NpZ25tZW50/model"
+	"a21hc3NpZ25tZW50/quran"
+	"a21hc3NpZ25tZW50/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func registerMemorizeRoutes(protected *gin.RouterGroup, svc *service.Service) {
+	protected.GET("/memorizes", func(c *gin.Context) {
+		user, ok := currentUser(c, svc)
+		if !ok {
+			return
+		}
+
+		memorizes, err := svc.GetMemorizes(user)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, memorizes)
+	})
+
+	protected.GET("/memorizes/:id", func(c *gin.Context) {
+		memorizeID, ok := parseIDParam(c, "id")
+		if !ok {
+			return
+		}
+
+		user, ok := currentUser(c, svc)
+		if !ok {
+			return
+		}
+
+		memorize, err := svc.GetMemorize(user, memorizeID)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		if c.Query("include") == "text" {
+			// The record is still returned when the text isn't
+			// available, with the reason in TextError.
+			response := struct {
+				model.Memorize
+				Text      []quran.Verse `json:",omitempty"`
+				TextError string        `json:",omitempty"`
+			}{Memorize: memorize}
+			response.Text, err = service.MemorizeText(memorize)
+			if err != nil {
+				response.TextError = err.Error()
+			}
+			c.JSON(http.StatusOK, response)
+			return
+		}
+		c.JSON(http.StatusOK, memorize)
+	})
+
+	protected.POST("/memorizes", func(c *gin.Context) {
+		var input service.MemorizeInput
+		if !bindJSON(c, &input) {
+			return
+		}
+
+		user, ok := currentUser(c, svc)
+		if !ok {
+			return
+		}
+
+		// A page range becomes one record per surah on those pages
+		memorizes, err := svc.AddMemorizes(user, input)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		memorizeIDs := []uint{}
+		for _, memorize := range memorizes {
+			memorizeIDs = append(memorizeIDs, memorize.ID)
+		}
+		c.JSON(http.StatusCreated, gin.H{"memorize_id": memorizeIDs[0], "memorize_ids": memorizeIDs})
+	})
+
+	protected.DELETE("/memorizes/:id", func(c *gin.Context) {
+		memorizeID, ok := parseIDParam(c, "id")
+		if !ok {
+			return
+		}
+
+		user, ok := currentUser(c, svc)
+		if !ok {
+			return
+		}
+
+		if err := svc.DeleteMemorize(user, memorizeID); err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "Memorize record deleted"})
+	})
+
+	protected.PUT("/memorizes/:id", func(c *gin.Context) {
+		memorizeID, ok := parseIDParam(c, "id")
+		if !ok {
+			return
+		}
+
+		var input service.MemorizeInput
+		if !bindJSON(c, &input) {
+			return
+		}
+
+		user, ok := currentUser(c, svc)
+		if !ok {
+			return
+		}
+
+		memorize, err := svc.UpdateMemorize(user, memorizeID, input)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, memorize)
+	})
+}
@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"a21hc3NpZ25tZW50/metrics"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// MetricsMiddleware counts and times every request by its route template,
+// so /memorizes/1 and /memorizes/2 are one route.
+func MetricsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		// Unknown paths are lumped together so they can't add a route each.
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		method := c.Request.Method
+		metrics.HTTPRequests.WithLabelValues(method, route, strconv.Itoa(c.Writer.Status())).Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+	}
+}
+
+// registerMetricsRoutes serves the metrics for Prometheus to scrape. It
+// doesn't need a login.
+func registerMetricsRoutes(router *gin.Engine) {
+	router.GET("/metrics", gin.WrapH(promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{})))
+}
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"a21hc3NpZ25tZW50/service"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func registerQuizRoutes(protected *gin.RouterGroup, svc *service.Service) {
+	protected.POST("/quizzes", func(c *gin.Context) {
+		var input service.QuizInput
+		// The body is optional; an empty one asks for the default quiz.
+		if c.Request.ContentLength != 0 {
+			if !bindJSON(c, &input) {
+				return
+			}
+		}
+
+		user, ok := currentUser(c, svc)
+		if !ok {
+			return
+		}
+
+		random := rand.New(rand.NewSource(time.Now().UnixNano()))
+		quiz, err := svc.GenerateQuiz(user, input, random)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusCreated, quiz)
+	})
+
+	protected.GET("/quizzes", func(c *gin.Context) {
+		user, ok := currentUser(c, svc)
+		if !ok {
+			return
+		}
+
+		quizzes, err := svc.GetQuizzes(user)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, quizzes)
+	})
+
+	protected.GET("/quizzes/:id", func(c *gin.Context) {
+		quizID, ok := parseIDParam(c, "id")
+		if !ok {
+			return
+		}
+
+		user, ok := currentUser(c, svc)
+		if !ok {
+			return
+		}
+
+		quiz, err := svc.GetQuiz(user, quizID)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, quiz)
+	})
+
+	protected.POST("/quizzes/:id/answers", func(c *gin.Context) {
+		quizID, ok := parseIDParam(c, "id")
+		if !ok {
+			return
+		}
+
+		var input struct {
+			Answers []service.QuizAnswer
+		}
+		if !bindJSON(c, &input) {
+			return
+		}
+
+		user, ok := currentUser(c, svc)
+		if !ok {
+			return
+		}
+
+		quiz, err := svc.SubmitQuiz(user, quizID, input.Answers, time.Now())
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, quiz)
+	})
+}
@@ -0,0 +1,30 @@
+package handler
+
+import (
+	"a21hc3NpZ25tZW50/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// registerQuranRoutes serves the Quran text. It doesn't need a login.
+func registerQuranRoutes(router *gin.RouterGroup) {
+	router.GET("/quran/:surah", func(c *gin.Context) {
+		text, err := service.GetQuranText(c.Param("surah"), "")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, text)
+	})
+
+	// :ayah is a single ayah ("5") or a range ("1-7").
+	router.GET("/quran/:surah/:ayah", func(c *gin.Context) {
+		text, err := service.GetQuranText(c.Param("surah"), c.Param("ayah"))
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, text)
+	})
+}
@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"a21hc3NpZ25tZW50/service"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func registerReadingRoutes(protected *gin.RouterGroup, svc *service.Service) {
+	protected.POST("/readings", func(c *gin.Context) {
+		var input service.ReadingInput
+		if !bindJSON(c, &input) {
+			return
+		}
+
+		user, ok := currentUser(c, svc)
+		if !ok {
+			return
+		}
+
+		reading, err := svc.AddReading(user, input, time.Now())
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusCreated, reading)
+	})
+
+	protected.GET("/readings", func(c *gin.Context) {
+		user, ok := currentUser(c, svc)
+		if !ok {
+			return
+		}
+
+		readings, err := svc.GetReadings(user)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, readings)
+	})
+
+	protected.GET("/readings/:id", func(c *gin.Context) {
+		readingID, ok := parseIDParam(c, "id")
+		if !ok {
+			return
+		}
+
+		user, ok := currentUser(c, svc)
+		if !ok {
+			return
+		}
+
+		reading, err := svc.GetReading(user, readingID)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, reading)
+	})
+
+	protected.PUT("/readings/:id", func(c *gin.Context) {
+		readingID, ok := parseIDParam(c, "id")
+		if !ok {
+			return
+		}
+
+		var input service.ReadingInput
+		if !bindJSON(c, &input) {
+			return
+		}
+
+		user, ok := currentUser(c, svc)
+		if !ok {
+			return
+		}
+
+		reading, err := svc.UpdateReading(user, readingID, input, time.Now())
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, reading)
+	})
+
+	protected.DELETE("/readings/:id", func(c *gin.Context) {
+		readingID, ok := parseIDParam(c, "id")
+		if !ok {
+			return
+		}
+
+		user, ok := currentUser(c, svc)
+		if !ok {
+			return
+		}
+
+		if err := svc.DeleteReading(user, readingID); err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "Reading deleted"})
+	})
+
+	protected.GET("/me/readings/stats", func(c *gin.Context) {
+		user, ok := currentUser(c, svc)
+		if !ok {
+			return
+		}
+
+		stats, err := svc.GetReadingStats(user)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, stats)
+	})
+}
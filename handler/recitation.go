@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"a21hc3NpZ25tZW50/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func registerRecitationRoutes(protected *gin.RouterGroup, svc *service.Service) {
+	protected.POST("/memorizes/:id/check", func(c *gin.Context) {
+		memorizeID, ok := parseIDParam(c, "id")
+		if !ok {
+			return
+		}
+
+		var input service.RecitationInput
+		if !bindJSON(c, &input) {
+			return
+		}
+
+		user, ok := currentUser(c, svc)
+		if !ok {
+			return
+		}
+
+		check, err := svc.CheckRecitation(user, memorizeID, input)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, check)
+	})
+}
@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"a21hc3NpZ25tZW50/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func registerRecordingRoutes(protected *gin.RouterGroup, svc *service.Service) {
+	// The audio is uploaded as multipart form data in the "audio" field.
+	protected.POST("/memorizes/:id/recordings", func(c *gin.Context) {
+		memorizeID, ok := parseIDParam(c, "id")
+		if !ok {
+			return
+		}
+
+		// Leave room for the rest of the form around the file.
+		limit := service.MaxRecordingSize + 1<<20
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+		header, err := c.FormFile("audio")
+		if err != nil {
+			if c.Request.ContentLength > limit {
+				c.Error(service.ErrRecordingTooLarge)
+				return
+			}
+			c.Error(badRequest("audio file is required"))
+			return
+		}
+		file, err := header.Open()
+		if err != nil {
+			c.Error(badRequest(err.Error()))
+			return
+		}
+		defer file.Close()
+
+		user, ok := currentUser(c, svc)
+		if !ok {
+			return
+		}
+
+		recording, err := svc.AddRecording(user, memorizeID, service.RecordingUpload{
+			FileName: header.Filename,
+			Size:     header.Size,
+			Content:  file,
+		})
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusCreated, recording)
+	})
+
+	protected.GET("/memorizes/:id/recordings", func(c *gin.Context) {
+		memorizeID, ok := parseIDParam(c, "id")
+		if !ok {
+			return
+		}
+
+		user, ok := currentUser(c, svc)
+		if !ok {
+			return
+		}
+
+		recordings, err := svc.GetRecordings(user, memorizeID)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, recordings)
+	})
+
+	// Streams the audio. Range requests are supported so players can seek.
+	protected.GET("/recordings/:id/audio", func(c *gin.Context) {
+		recordingID, ok := parseIDParam(c, "id")
+		if !ok {
+			return
+		}
+
+		user, ok := currentUser(c, svc)
+		if !ok {
+			return
+		}
+
+		recording, file, err := svc.OpenRecording(user, recordingID)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		defer file.Close()
+
+		c.Header("Content-Type", recording.ContentType)
+		http.ServeContent(c.Writer, c.Request, recording.FileName, recording.CreatedAt, file)
+	})
+
+	protected.DELETE("/recordings/:id", func(c *gin.Context) {
+		recordingID, ok := parseIDParam(c, "id")
+		if !ok {
+			return
+		}
+
+		user, ok := currentUser(c, svc)
+		if !ok {
+			return
+		}
+
+		if err := svc.DeleteRecording(user, recordingID); err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "Recording deleted"})
+	})
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"a21hc3NpZ25tZW50/service"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func registerRotationRoutes(protected *gin.RouterGroup, svc *service.Service) {
+	protected.PUT("/me/rotation", func(c *gin.Context) {
+		var input service.RotationInput
+		if !bindJSON(c, &input) {
+			return
+		}
+
+		user, ok := currentUser(c, svc)
+		if !ok {
+			return
+		}
+
+		rotation, err := svc.SetRotation(user, input, time.Now())
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, rotation)
+	})
+
+	protected.GET("/me/rotation", func(c *gin.Context) {
+		user, ok := currentUser(c, svc)
+		if !ok {
+			return
+		}
+
+		plan, err := svc.GetRotation(user, time.Now())
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, plan)
+	})
+
+	protected.GET("/me/rotation/today", func(c *gin.Context) {
+		user, ok := currentUser(c, svc)
+		if !ok {
+			return
+		}
+
+		plan, err := svc.GetRotation(user, time.Now())
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, plan.Today)
+	})
+}
@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"a21hc3NpZ25tZW50/service"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func registerStatsRoutes(protected *gin.RouterGroup, svc *service.Service) {
+	protected.GET("/me/stats", func(c *gin.Context) {
+		user, ok := currentUser(c, svc)
+		if !ok {
+			return
+		}
+
+		stats, err := svc.GetStats(user)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, stats)
+	})
+
+	protected.GET("/me/streak", func(c *gin.Context) {
+		user, ok := currentUser(c, svc)
+		if !ok {
+			return
+		}
+
+		streak, err := svc.GetStreak(user, c.Query("tz"), time.Now())
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, streak)
+	})
+
+	protected.GET("/me/activity", func(c *gin.Context) {
+		user, ok := currentUser(c, svc)
+		if !ok {
+			return
+		}
+
+		calendar, err := svc.GetActivityCalendar(user, c.Query("from"), c.Query("to"), c.Query("tz"), time.Now())
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, calendar)
+	})
+}
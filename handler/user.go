@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"a21hc3NpZ25tZW50/service"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gin-gonic/gin"
+)
+
+var jwtSecret = []byte("helloWorld") // secret key
+
+func AuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authHeader := c.GetHeader("Authorization")
+		if authHeader == "" {
+			c.Error(unauthorized("Unauthorized"))
+			c.Abort()
+			return
+		}
+
+		tokenString := authHeader[len("Bearer "):]
+		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+			}
+			return jwtSecret, nil
+		})
+
+		if err != nil || !token.Valid {
+			c.Error(unauthorized("Invalid token"))
+			c.Abort()
+			return
+		}
+
+		claims, ok := token.Claims.(jwt.MapClaims)
+		if !ok || claims["username"] == "" {
+			c.Error(unauthorized("Invalid token claims"))
+			c.Abort()
+			return
+		}
+
+		// Set user information in the context
+		c.Set("username", claims["username"])
+		c.Next()
+	}
+}
+
+func registerUserRoutes(router *gin.RouterGroup, svc *service.Service) {
+	router.POST("/users", func(c *gin.Context) {
+		var input service.UserInput
+		if !bindJSON(c, &input) {
+			return
+		}
+
+		user, err := svc.Register(input)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{"status": "Created", "User": user})
+	})
+
+	router.POST("/signin", func(c *gin.Context) {
+		var credentials struct {
+			Username string `json:"username"`
+			Password string `json:"password"`
+		}
+		if !bindJSON(c, &credentials) {
+			return
+		}
+
+		user, err := svc.Login(credentials.Username, credentials.Password)
+		if err != nil {
+			if errors.Is(err, service.ErrInvalidCredentials) {
+				err = unauthorized("Invalid username or password")
+			}
+			c.Error(err)
+			return
+		}
+
+		// Create JWT token
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"username": user.Username,
+			"exp":      time.Now().Add(time.Hour * 24).Unix(), // Token expiration
+		})
+
+		tokenString, err := token.SignedString(jwtSecret)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"status": "Logged in",
+			"token":  tokenString,
+		})
+	})
+}
@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"a21hc3NpZ25tZW50/model"
+	"a21hc3NpZ25tZW50/quran"
+	"a21hc3NpZ25tZW50/review"
+	"a21hc3NpZ25tZW50/service"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9_.]{3,30}$`)
+
+var registerValidatorsOnce sync.Once
+
+// registerValidators adds the rules used in the binding tags of request
+// bodies to gin's validator.
+func registerValidators() {
+	registerValidatorsOnce.Do(func() {
+		validate, ok := binding.Validator.Engine().(*validator.Validate)
+		if !ok {
+			return
+		}
+		rules := map[string]validator.Func{
+			"username": func(fl validator.FieldLevel) bool {
+				return usernamePattern.MatchString(fl.Field().String())
+			},
+			"timezone": func(fl validator.FieldLevel) bool {
+				_, err := time.LoadLocation(fl.Field().String())
+				return err == nil
+			},
+			"surah": func(fl validator.FieldLevel) bool {
+				_, ok := quran.LookupSurah(fl.Field().String())
+				return ok
+			},
+			"ayah_range": func(fl validator.FieldLevel) bool {
+				_, err := quran.ParseAyahRange(fl.Field().String())
+				return err == nil
+			},
+			"page_range": func(fl validator.FieldLevel) bool {
+				_, err := quran.ParsePageRange(fl.Field().String())
+				return err == nil
+			},
+			"accuracy": func(fl validator.FieldLevel) bool {
+				score := fl.Field().Float()
+				return model.ValidateAccuracy(&score) == nil
+			},
+			"review_frequency": func(fl validator.FieldLevel) bool {
+				_, err := review.ParseFrequency(fl.Field().String())
+				return err == nil
+			},
+		}
+		for tag, rule := range rules {
+			if err := validate.RegisterValidation(tag, rule); err != nil {
+				panic(err)
+			}
+		}
+		validate.RegisterStructValidation(validateMemorizeInput, service.MemorizeInput{})
+	})
+}
+
+// validateMemorizeInput checks that the ayah range is within the surah. Each
+// is checked on its own by its field's rules first.
+func validateMemorizeInput(sl validator.StructLevel) {
+	input := sl.Current().Interface().(service.MemorizeInput)
+	surah, ok := quran.LookupSurah(input.SurahName)
+	if !ok {
+		return
+	}
+	ayahs, err := quran.ParseAyahRange(input.AyahRange)
+	if err != nil {
+		return
+	}
+	if surah.Validate(ayahs) != nil {
+		sl.ReportError(input.AyahRange, "AyahRange", "AyahRange", "within_surah", strconv.Itoa(surah.AyahCount))
+	}
+}
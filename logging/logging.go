@@ -0,0 +1,67 @@
+// Package logging sets up the structured JSON logs of the server.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// Level is the minimum level that is logged. It can be changed while the
+// server is running.
+var Level = new(slog.LevelVar)
+
+// Redacted replaces the value of a sensitive attribute.
+const Redacted = "[REDACTED]"
+
+// sensitiveKeys are parts of attribute names whose values are never logged.
+var sensitiveKeys = []string{"password", "token", "secret", "authorization", "cookie"}
+
+// NewHandler writes records as JSON lines to w, at Level and above, with
+// sensitive attributes redacted.
+func NewHandler(w io.Writer) slog.Handler {
+	return slog.NewJSONHandler(w, &slog.HandlerOptions{
+		Level:       Level,
+		ReplaceAttr: redact,
+	})
+}
+
+// Setup makes the default logger, which the log package also writes to, log
+// to w at the given level.
+func Setup(w io.Writer, level string) error {
+	if err := SetLevel(level); err != nil {
+		return err
+	}
+	slog.SetDefault(slog.New(NewHandler(w)))
+	return nil
+}
+
+// SetLevel changes Level to a level such as "debug" or "warn". An empty level
+// is info.
+func SetLevel(level string) error {
+	if level == "" {
+		level = "info"
+	}
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		return fmt.Errorf("invalid log level %q: must be debug, info, warn or error", level)
+	}
+	Level.Set(l)
+	return nil
+}
+
+// LevelName is the current Level in the form SetLevel takes.
+func LevelName() string {
+	return strings.ToLower(Level.Level().String())
+}
+
+func redact(groups []string, attr slog.Attr) slog.Attr {
+	key := strings.ToLower(attr.Key)
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return slog.String(attr.Key, Redacted)
+		}
+	}
+	return attr
+}
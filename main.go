@@ -1,26 +1,45 @@
 package main
 
 import (
+	"a21hc3NpZ25tZW50/handler"
+	"a21hc3NpZ25tZW50/logging"
+	"a21hc3NpZ25tZW50/metrics"
+	"a21hc3NpZ25tZW50/migration"
 	"a21hc3NpZ25tZW50/model"
-	"a21hc3NpZ25tZW50/repository/authRepository"
+	"a21hc3NpZ25tZW50/quran"
+	"a21hc3NpZ25tZW50/repository"
 	dbRepository "a21hc3NpZ25tZW50/repository/dbRepository"
 	"a21hc3NpZ25tZW50/service"
+	"a21hc3NpZ25tZW50/storage"
+	"errors"
 	"fmt"
 	"log"
-	"net/http"
-
+	"log/slog"
+	"net/url"
+	"os"
+	"reflect"
 	"time"
+	_ "time/tzdata"
 
-	"github.com/dgrijalva/jwt-go"
-	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
 	_ "github.com/lib/pq"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
+// Databases Connect can use.
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// Credential says which database to connect to. Driver defaults to
+// DriverPostgres. For DriverSQLite only DatabaseName is used, as the path of
+// the database file.
 type Credential struct {
+	Driver       string
 	Host         string
 	Username     string
 	Password     string
@@ -29,283 +48,203 @@ type Credential struct {
 	Schema       string
 }
 
-var jwtSecret = []byte("helloWorld") // secret key
-
-func AuthMiddleware(authRepo *authRepository.Repository) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-			c.Abort()
-			return
-		}
+func Connect(creds *Credential) (*gorm.DB, error) {
+	switch creds.Driver {
+	case "", DriverPostgres:
+		dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%d sslmode=disable TimeZone=Asia/Jakarta",
+			creds.Host, creds.Username, creds.Password, creds.DatabaseName, creds.Port)
 
-		tokenString := authHeader[len("Bearer "):]
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
-			return jwtSecret, nil
+		dbConn, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+			Logger: logger.Default.LogMode(logger.Silent),
 		})
-
-		if err != nil || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
-			c.Abort()
-			return
-		}
-
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok || claims["username"] == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
-			c.Abort()
-			return
+		if err != nil {
+			return nil, err
 		}
-
-		// Set user information in the context
-		c.Set("username", claims["username"])
-		c.Next()
+		return dbConn, nil
+	case DriverSQLite:
+		return connectSQLite(creds.DatabaseName)
+	default:
+		return nil, fmt.Errorf("unknown database driver %q", creds.Driver)
 	}
 }
 
-func Connect(creds *Credential) (*gorm.DB, error) {
-	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%d sslmode=disable TimeZone=Asia/Jakarta",
-		creds.Host, creds.Username, creds.Password, creds.DatabaseName, creds.Port)
-
-	dbConn, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Silent),
+func connectSQLite(path string) (*gorm.DB, error) {
+	if path == "" {
+		return nil, errors.New("sqlite needs a database file")
+	}
+	params := url.Values{"_pragma": {"busy_timeout(5000)", "journal_mode(WAL)"}}
+	dbConn, err := gorm.Open(sqlite.Open("file:"+path+"?"+params.Encode()), &gorm.Config{
+		Logger:  logger.Default.LogMode(logger.Silent),
+		NowFunc: func() time.Time { return time.Now().UTC() },
 	})
 	if err != nil {
 		return nil, err
 	}
-	return dbConn, nil
-}
-
-func SetupRouter(dbRepo *dbRepository.Repository, authRepo *authRepository.Repository) *gin.Engine {
-	svc := service.NewService(*dbRepo, authRepo)
-	router := gin.Default()
 
-	// Enable CORS for all origins, methods, and headers
-	router.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"http://localhost:3000"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
-		AllowCredentials: true,
-	}))
+	// SQLite allows one writer at a time; with a single connection writes
+	// queue up here instead of failing with "database is locked".
+	sqlDB, err := dbConn.DB()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(1)
 
-	router.GET("/health", func(c *gin.Context) {
-		c.String(http.StatusOK, "OK")
-	})
+	// SQLite stores times as text with their offset and compares them as
+	// text, so they are all stored in UTC to keep them in order. The
+	// repository passes times in queries in UTC too.
+	if err := dbConn.Callback().Create().Before("gorm:create").Register("app:utc_times", utcTimes); err != nil {
+		return nil, err
+	}
+	if err := dbConn.Callback().Update().Before("gorm:update").Register("app:utc_times", utcTimes); err != nil {
+		return nil, err
+	}
+	return dbConn, nil
+}
 
-	router.POST("/users", func(c *gin.Context) {
-		var user model.User
-		if err := c.ShouldBindJSON(&user); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
+// utcTimes converts the time fields of the records being saved to UTC.
+func utcTimes(db *gorm.DB) {
+	if db.Statement.Schema == nil {
+		return
+	}
+	ctx := db.Statement.Context
+	convert := func(record reflect.Value) {
+		for _, field := range db.Statement.Schema.Fields {
+			value, zero := field.ValueOf(ctx, record)
+			if zero {
+				continue
+			}
+			switch t := value.(type) {
+			case time.Time:
+				db.AddError(field.Set(ctx, record, t.UTC()))
+			case *time.Time:
+				utc := t.UTC()
+				db.AddError(field.Set(ctx, record, &utc))
+			}
 		}
+	}
 
-		err := svc.Register(user)
-		if err != nil {
-			if err.Error() == "username already registered" {
-				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
-			} else {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			}
-			return
+	switch records := db.Statement.ReflectValue; records.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < records.Len(); i++ {
+			convert(reflect.Indirect(records.Index(i)))
 		}
+	case reflect.Struct:
+		convert(records)
+	}
+}
 
-		c.JSON(http.StatusCreated, gin.H{"status": "Created", "User": user})
-	})
+func SetupRouter(repo repository.Repository) *gin.Engine {
+	svc := service.NewService(repo)
+	if dir := os.Getenv("RECORDINGS_DIR"); dir != "" {
+		svc.SetFileStore(storage.NewLocalStore(dir))
+	}
+	return handler.NewRouter(svc)
+}
 
-	router.POST("/signin", func(c *gin.Context) {
-		var credentials struct {
-			Username string `json:"username"`
-			Password string `json:"password"`
-		}
-		if err := c.ShouldBindJSON(&credentials); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
+func main() {
+	if err := logging.Setup(os.Stdout, os.Getenv("LOG_LEVEL")); err != nil {
+		log.Fatal(err)
+	}
+	// gin's debug mode prints plain-text lines next to the JSON logs
+	gin.SetMode(gin.ReleaseMode)
+	handler.LogAdminToken = os.Getenv("LOG_ADMIN_TOKEN")
 
-		user, err := dbRepo.GetUserByUsername(credentials.Username)
+	if bands := os.Getenv("ACCURACY_GRADE_BANDS"); bands != "" {
+		gradeBands, err := model.ParseGradeBands(bands)
 		if err != nil {
-			log.Printf("Error fetching user: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching user data"})
-			return
-		}
-
-		if user.Username == "" || user.Password != credentials.Password {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid username or password"})
-			return
+			log.Fatal(err)
 		}
+		model.GradeBands = gradeBands
+	}
 
-		// Create JWT token
-		token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-			"username": user.Username,
-			"exp":      time.Now().Add(time.Hour * 24).Unix(), // Token expiration
-		})
-
-		tokenString, err := token.SignedString(jwtSecret)
+	if path := os.Getenv("QURAN_TEXT_FILE"); path != "" {
+		corpus, err := quran.LoadTanzilFile(path)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
-			return
+			log.Fatal("failed loading Quran text: " + err.Error())
 		}
-
-		c.JSON(http.StatusOK, gin.H{
-			"status": "Logged in",
-			"token":  tokenString,
-		})
-	})
-
-	protected := router.Group("/")
-	protected.Use(AuthMiddleware(authRepo))
-	{
-		protected.GET("/memorizes", func(c *gin.Context) {
-			// Retrieve the username from the context
-			username := c.GetString("username")
-
-			// Fetch memorizes for the user
-			memorizes, err := dbRepo.GetAllMemorizesByUser(username)
-			if err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-				return
-			}
-
-			c.JSON(http.StatusOK, memorizes)
-		})
-
-		protected.GET("/memorizes/:id", func(c *gin.Context) {
-			id := c.Param("id")
-			memorizeID := 0
-			fmt.Sscanf(id, "%d", &memorizeID)
-			memorize, err := dbRepo.GetMemorizeByID(uint(memorizeID))
-			if err != nil {
-				c.JSON(http.StatusNotFound, gin.H{"error": "Memorize record not found"})
-				return
-			}
-			c.JSON(http.StatusOK, memorize)
-		})
-
-		protected.POST("/memorizes", func(c *gin.Context) {
-			var memorize model.Memorize
-
-			// Bind the JSON request body to the `memorize` struct
-			if err := c.ShouldBindJSON(&memorize); err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-				return
-			}
-
-			// Get the logged-in username from the context
-			username := c.GetString("username")
-
-			// Retrieve the user by username from the database
-			user, err := dbRepo.GetUserByUsername(username)
-			if err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user"})
-				return
-			}
-
-			// Set the UserID field for the memorize record
-			memorize.UserID = user.ID
-
-			// Add the memorize record to the database
-			memorizeID, err := dbRepo.AddMemorize(memorize)
-			if err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-				return
-			}
-
-			// Return the created memorize record ID
-			c.JSON(http.StatusCreated, gin.H{"memorize_id": memorizeID})
-		})
-
-		protected.DELETE("/memorizes/:id", func(c *gin.Context) {
-			id := c.Param("id")
-			memorizeID := 0
-			fmt.Sscanf(id, "%d", &memorizeID)
-			err := dbRepo.DeleteMemorize(uint(memorizeID))
-			if err != nil {
-				c.JSON(http.StatusNotFound, gin.H{"error": "Memorize record not found"})
-				return
-			}
-			c.JSON(http.StatusOK, gin.H{"status": "Memorize record deleted"})
-		})
-
-		protected.PUT("/memorizes/:id", func(c *gin.Context) {
-			id := c.Param("id")
-			memorizeID := 0
-			fmt.Sscanf(id, "%d", &memorizeID)
-
-			// Retrieve the existing memorize record by ID
-			existingMemorize, err := dbRepo.GetMemorizeByID(uint(memorizeID))
-			if err != nil {
-				c.JSON(http.StatusNotFound, gin.H{"error": "Memorize record not found"})
-				return
-			}
-
-			// Bind the JSON request body to the memorize struct
-			var updatedMemorize model.Memorize
-			if err := c.ShouldBindJSON(&updatedMemorize); err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-				return
-			}
-
-			// Update the existing memorize fields with the new data
-			existingMemorize.SurahName = updatedMemorize.SurahName
-			existingMemorize.AyahRange = updatedMemorize.AyahRange
-			existingMemorize.TotalAyah = updatedMemorize.TotalAyah
-			existingMemorize.DateStarted = updatedMemorize.DateStarted
-			existingMemorize.DateCompleted = updatedMemorize.DateCompleted
-			existingMemorize.ReviewFrequency = updatedMemorize.ReviewFrequency
-			existingMemorize.LastReviewDate = updatedMemorize.LastReviewDate
-			existingMemorize.AccuracyLevel = updatedMemorize.AccuracyLevel
-			existingMemorize.NextReviewDate = updatedMemorize.NextReviewDate
-			existingMemorize.Notes = updatedMemorize.Notes
-
-			// Save the updated memorize record to the database
-			err = dbRepo.UpdateMemorize(existingMemorize)
-			if err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update memorize record"})
-				return
-			}
-
-			c.JSON(http.StatusOK, existingMemorize)
-		})
-
+		quran.Text = corpus
+	}
+	if !quran.Text.Complete() {
+		log.Fatalf("the Quran text has %d of %d ayahs; run go generate ./quran or set QURAN_TEXT_FILE to a complete Tanzil text file", quran.Text.Len(), quran.TotalAyahs)
 	}
 
-	router.NoRoute(func(c *gin.Context) {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Page not found"})
-	})
-
-	return router
-}
-
-func main() {
 	dbCredential := Credential{
+		Driver:       os.Getenv("DB_DRIVER"),
 		Host:         "localhost",
 		Username:     "postgres",
 		Password:     "password",
 		DatabaseName: "kampusmerdeka",
 		Port:         5432,
 	}
+	if dbCredential.Driver == DriverSQLite {
+		dbCredential.DatabaseName = "kampusmerdeka.db"
+		if path := os.Getenv("SQLITE_PATH"); path != "" {
+			dbCredential.DatabaseName = path
+		}
+	}
 
 	// Connect to the database
 	dbConn, err := Connect(&dbCredential)
 	if err != nil {
 		log.Fatal(err)
 	}
+	if err := metrics.InstrumentDB(dbConn, dbCredential.DatabaseName); err != nil {
+		log.Fatal("failed instrumenting database: " + err.Error())
+	}
 
-	// Drop the tables if they exist
-	if err = dbConn.Migrator().DropTable("users", "memorizes"); err != nil {
-		log.Fatal("failed dropping table:" + err.Error())
+	// "set-role <username> <student|teacher>" changes a user's role and
+	// exits. It is the only way to make a teacher, as users register as
+	// students.
+	if len(os.Args) > 1 && os.Args[1] == "set-role" {
+		if len(os.Args) != 4 {
+			log.Fatal("usage: set-role <username> <student|teacher>")
+		}
+		if err := migration.Migrate(dbConn); err != nil {
+			log.Fatal("failed migrating table:" + err.Error())
+		}
+		svc := service.NewService(dbRepository.NewRepository(dbConn))
+		if err := svc.SetRole(os.Args[2], os.Args[3]); err != nil {
+			log.Fatal(err)
+		}
+		slog.Info("role changed", "username", os.Args[2], "role", os.Args[3])
+		return
 	}
 
-	// Auto-migrate to create tables
-	if err = dbConn.AutoMigrate(&model.User{}, &model.Memorize{}); err != nil {
+	// Drop the tables only when asked to. Otherwise the data is kept and
+	// Migrate converts what older versions wrote.
+	if os.Getenv("RESET_DATABASE") == "true" {
+		slog.Warn("RESET_DATABASE is set, dropping every table")
+		if err = migration.DropAll(dbConn); err != nil {
+			log.Fatal("failed dropping table:" + err.Error())
+		}
+	}
+
+	// Create the tables and convert data from older versions
+	if err = migration.Migrate(dbConn); err != nil {
 		log.Fatal("failed migrating table:" + err.Error())
 	}
 
-	// Insert dummy data
+	// Insert dummy data into a new database, one without any users
+	var users int64
+	if err := dbConn.Model(&model.User{}).Count(&users).Error; err != nil {
+		log.Fatal("failed counting users: " + err.Error())
+	}
+	if users == 0 {
+		if err := addDummyData(dbConn); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// Set up the repository and router
+	dbRepo := dbRepository.NewRepository(dbConn)
+	router := SetupRouter(dbRepo)
+	if err := router.Run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// addDummyData adds the dummy users and memorizes described in the README.
+func addDummyData(dbConn *gorm.DB) error {
 	dummyUser := model.User{
 		Username: "john_doe",
 		Password: "password123", // You should hash the password in real implementation
@@ -314,18 +253,20 @@ func main() {
 	dummyUser2 := model.User{
 		Username: "jane_doe",
 		Password: "password456", // You should hash the password in real implementation
+		Role:     model.RoleTeacher,
 	}
 
 	// Add dummy users to the database
 	if err := dbConn.Create(&dummyUser).Error; err != nil {
-		log.Fatal("failed adding dummy user 1: " + err.Error())
+		return errors.New("failed adding dummy user 1: " + err.Error())
 	}
 
 	if err := dbConn.Create(&dummyUser2).Error; err != nil {
-		log.Fatal("failed adding dummy user 2: " + err.Error())
+		return errors.New("failed adding dummy user 2: " + err.Error())
 	}
 
 	// Add memorizes for the first dummy user
+	dummyAccuracy := 95.0
 	dummyMemorize := model.Memorize{
 		UserID:          dummyUser.ID,
 		SurahName:       "Al-Fatiha",
@@ -333,9 +274,9 @@ func main() {
 		TotalAyah:       7,
 		DateStarted:     time.Now(),
 		DateCompleted:   time.Now().AddDate(0, 0, 7),
-		ReviewFrequency: "Weekly",
+		ReviewFrequency: "weekly",
 		LastReviewDate:  time.Now(),
-		AccuracyLevel:   "95",
+		AccuracyScore:   &dummyAccuracy,
 		NextReviewDate:  time.Now().AddDate(0, 0, 7),
 		Notes:           "Review after one week",
 	}
@@ -347,25 +288,20 @@ func main() {
 		TotalAyah:       5,
 		DateStarted:     time.Now(),
 		DateCompleted:   time.Now().AddDate(0, 0, 14),
-		ReviewFrequency: "Biweekly",
+		ReviewFrequency: "biweekly",
 		LastReviewDate:  time.Now(),
-		AccuracyLevel:   "95",
+		AccuracyScore:   &dummyAccuracy,
 		NextReviewDate:  time.Now().AddDate(0, 0, 14),
 		Notes:           "Review after two weeks",
 	}
 
 	// Add dummy memorizes to the database
 	if err := dbConn.Create(&dummyMemorize).Error; err != nil {
-		log.Fatal("failed adding dummy memorize 1: " + err.Error())
+		return errors.New("failed adding dummy memorize 1: " + err.Error())
 	}
 
 	if err := dbConn.Create(&dummyMemorize2).Error; err != nil {
-		log.Fatal("failed adding dummy memorize 2: " + err.Error())
+		return errors.New("failed adding dummy memorize 2: " + err.Error())
 	}
-
-	// Set up repositories and router
-	authRepo := authRepository.NewRepository()
-	dbRepo := dbRepository.NewRepository(dbConn)
-	router := SetupRouter(dbRepo, authRepo)
-	router.Run()
+	return nil
 }
@@ -0,0 +1,62 @@
+// Package metrics collects the Prometheus metrics of the server: HTTP
+// requests, database queries and connections, and how the app is used.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+// Registry holds every metric of the server, served at /metrics.
+var Registry = prometheus.NewRegistry()
+
+var (
+	// HTTPRequests counts requests by method, route template (such as
+	// /api/v1/memorizes/:id) and status code.
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP requests by method, route template and status code.",
+	}, []string{"method", "route", "status"})
+
+	// HTTPRequestDuration is how long requests took to answer, by method and
+	// route template.
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Time taken to answer HTTP requests by method and route template.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	// DBQueryDuration is how long database queries took, by operation
+	// (create, query, update, delete, row or raw) and table.
+	DBQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "db_query_duration_seconds",
+		Help:    "Time taken by database queries by operation and table.",
+		Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table"})
+
+	// MemorizesCreated counts the memorize records added. A page range adds
+	// one record per surah.
+	MemorizesCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "memorize_records_created_total",
+		Help: "Memorize records created.",
+	})
+
+	// ActivitiesLogged counts the memorization and review sessions logged,
+	// by type (memorized or reviewed).
+	ActivitiesLogged = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "activities_logged_total",
+		Help: "Memorization and review sessions logged by type.",
+	}, []string{"type"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequests,
+		HTTPRequestDuration,
+		DBQueryDuration,
+		MemorizesCreated,
+		ActivitiesLogged,
+	)
+}
@@ -0,0 +1,206 @@
+package migration
+
+import (
+	"a21hc3NpZ25tZW50/model"
+	"a21hc3NpZ25tZW50/review"
+	"log/slog"
+
+	"gorm.io/gorm"
+)
+
+// Models lists every table the API uses, in dependency order.
+var Models = []interface{}{
+	&model.User{},
+	&model.Memorize{},
+	&model.Group{},
+	&model.GroupMember{},
+	&model.Assignment{},
+	&model.AssignmentRecipient{},
+	&model.Evaluation{},
+	&model.EvaluationMistake{},
+	&model.Activity{},
+	&model.Goal{},
+	&model.Rotation{},
+	&model.Quiz{},
+	&model.QuizQuestion{},
+	&model.Recording{},
+	&model.Comment{},
+	&model.CommentRead{},
+	&model.Reading{},
+}
+
+// Migrate creates or updates the tables and then converts data written by
+// older versions of the API. It is safe to run on every startup.
+func Migrate(db *gorm.DB) error {
+	// The activity log is only backfilled when its table is new. Once it
+	// exists, an empty log just means nothing has happened yet.
+	newActivities := !db.Migrator().HasTable(&model.Activity{})
+	if err := db.AutoMigrate(Models...); err != nil {
+		return err
+	}
+	if err := dropCommentReadIndex(db); err != nil {
+		return err
+	}
+	if err := convertAccuracyLevel(db); err != nil {
+		return err
+	}
+	if err := normalizeReviewFrequency(db); err != nil {
+		return err
+	}
+	if err := backfillMemorizeSpans(db); err != nil {
+		return err
+	}
+	if newActivities {
+		return backfillActivities(db)
+	}
+	return nil
+}
+
+// DropAll drops every table created by Migrate.
+func DropAll(db *gorm.DB) error {
+	for i := len(Models) - 1; i >= 0; i-- {
+		if err := db.Migrator().DropTable(Models[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// convertAccuracyLevel moves the old free-text memorizes.accuracy_level
+// column ("95", "High", ...) into the numeric accuracy_score column and drops
+// it. Values that can't be mapped are left empty.
+func convertAccuracyLevel(db *gorm.DB) error {
+	if !db.Migrator().HasColumn(&model.Memorize{}, "accuracy_level") {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		var rows []struct {
+			ID            uint
+			AccuracyLevel string
+		}
+		err := tx.Table("memorizes").
+			Select("id, accuracy_level").
+			Where("accuracy_score IS NULL AND accuracy_level IS NOT NULL AND accuracy_level <> ''").
+			Scan(&rows).Error
+		if err != nil {
+			return err
+		}
+
+		unmapped := 0
+		for _, row := range rows {
+			score, ok := model.ParseLegacyAccuracy(row.AccuracyLevel)
+			if !ok {
+				unmapped++
+				slog.Warn("migration: unrecognised accuracy level, leaving it empty", "memorize_id", row.ID, "accuracy_level", row.AccuracyLevel)
+				continue
+			}
+			err := tx.Table("memorizes").Where("id = ?", row.ID).Update("accuracy_score", score).Error
+			if err != nil {
+				return err
+			}
+		}
+		slog.Info("migration: converted accuracy levels", "converted", len(rows)-unmapped, "unrecognised", unmapped)
+
+		return tx.Exec("ALTER TABLE memorizes DROP COLUMN accuracy_level").Error
+	})
+}
+
+// dropCommentReadIndex drops the old unique index on comment_reads, which
+// allowed one read per memorize record and so no read per recording. The
+// index that replaces it also has recording_id.
+func dropCommentReadIndex(db *gorm.DB) error {
+	if !db.Migrator().HasIndex(&model.CommentRead{}, "idx_comment_read") {
+		return nil
+	}
+	return db.Migrator().DropIndex(&model.CommentRead{}, "idx_comment_read")
+}
+
+// normalizeReviewFrequency rewrites free-text review frequencies ("Weekly",
+// "every 3 days") in their canonical form. Values that aren't a valid
+// frequency are cleared.
+func normalizeReviewFrequency(db *gorm.DB) error {
+	var values []string
+	err := db.Model(&model.Memorize{}).Distinct().Where("review_frequency <> ''").Pluck("review_frequency", &values).Error
+	if err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, value := range values {
+			canonical := ""
+			if frequency, err := review.ParseFrequency(value); err == nil {
+				canonical = frequency.String()
+			} else {
+				slog.Warn("migration: clearing unrecognised review frequency", "review_frequency", value)
+			}
+			if canonical == value {
+				continue
+			}
+			err := tx.Model(&model.Memorize{}).Where("review_frequency = ?", value).Update("review_frequency", canonical).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// backfillMemorizeSpans fills in Memorize.SurahNumber, FirstAyah and LastAyah
+// for records saved before they existed.
+func backfillMemorizeSpans(db *gorm.DB) error {
+	var memorizes []model.Memorize
+	err := db.Where("surah_number = 0 AND surah_name <> ''").Find(&memorizes).Error
+	if err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, memorize := range memorizes {
+			if err := memorize.BeforeSave(tx); err != nil {
+				return err
+			}
+			if memorize.SurahNumber == 0 {
+				continue
+			}
+			err := tx.Model(&memorize).UpdateColumns(map[string]interface{}{
+				"surah_number": memorize.SurahNumber,
+				"first_ayah":   memorize.FirstAyah,
+				"last_ayah":    memorize.LastAyah,
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// backfillActivities seeds the activity log from the dates on existing
+// Memorize records. Migrate only calls it when it has just created the
+// activities table.
+func backfillActivities(db *gorm.DB) error {
+	var memorizes []model.Memorize
+	if err := db.Find(&memorizes).Error; err != nil {
+		return err
+	}
+
+	var activities []model.Activity
+	for _, memorize := range memorizes {
+		ayahs := memorize.TotalAyah
+		if span, ok := memorize.Span(); ok {
+			ayahs = span.Len()
+		}
+		id := memorize.ID
+		if memorize.IsCompleted() {
+			activities = append(activities, model.Activity{UserID: memorize.UserID, MemorizeID: &id, Type: model.ActivityMemorized, Ayahs: ayahs, OccurredAt: memorize.DateCompleted})
+		}
+		if !memorize.LastReviewDate.IsZero() {
+			activities = append(activities, model.Activity{UserID: memorize.UserID, MemorizeID: &id, Type: model.ActivityReviewed, Ayahs: ayahs, OccurredAt: memorize.LastReviewDate})
+		}
+	}
+	if len(activities) == 0 {
+		return nil
+	}
+	return db.CreateInBatches(activities, 100).Error
+}
@@ -0,0 +1,136 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// GradeBand is the lowest accuracy score that still earns Grade.
+type GradeBand struct {
+	Grade    string
+	MinScore float64
+}
+
+// GradeBands is used to derive Memorize.AccuracyGrade, highest band first.
+// It can be replaced at startup with ParseGradeBands.
+var GradeBands = []GradeBand{
+	{"A", 90},
+	{"B", 80},
+	{"C", 70},
+	{"D", 60},
+	{"E", 0},
+}
+
+// ParseGradeBands parses a band list such as "A:90,B:80,C:70,D:60,E:0".
+func ParseGradeBands(s string) ([]GradeBand, error) {
+	var bands []GradeBand
+	for _, part := range strings.Split(s, ",") {
+		grade, min, ok := strings.Cut(strings.TrimSpace(part), ":")
+		if !ok || strings.TrimSpace(grade) == "" {
+			return nil, fmt.Errorf("invalid grade band %q", part)
+		}
+		score, err := strconv.ParseFloat(strings.TrimSpace(min), 64)
+		if err != nil || score < 0 || score > 100 || math.IsNaN(score) {
+			return nil, fmt.Errorf("invalid minimum score in grade band %q", part)
+		}
+		bands = append(bands, GradeBand{Grade: strings.TrimSpace(grade), MinScore: score})
+	}
+	if len(bands) == 0 {
+		return nil, errors.New("no grade bands given")
+	}
+	sort.Slice(bands, func(i, j int) bool { return bands[i].MinScore > bands[j].MinScore })
+	return bands, nil
+}
+
+// Grade returns the letter grade for an accuracy score, or "" when the
+// record has not been assessed.
+func Grade(score *float64) string {
+	if score == nil {
+		return ""
+	}
+	for _, band := range GradeBands {
+		if *score >= band.MinScore {
+			return band.Grade
+		}
+	}
+	return ""
+}
+
+var ErrInvalidAccuracy = errors.New("accuracy score must be between 0 and 100")
+
+func ValidateAccuracy(score *float64) error {
+	if score != nil && (*score < 0 || *score > 100 || math.IsNaN(*score)) {
+		return ErrInvalidAccuracy
+	}
+	return nil
+}
+
+// legacyAccuracyLabels maps the free-text values the old AccuracyLevel column
+// accepted to a score.
+var legacyAccuracyLabels = map[string]float64{
+	"excellent":     95,
+	"mumtaz":        95,
+	"very good":     85,
+	"very high":     95,
+	"jayyid jiddan": 85,
+	"high":          90,
+	"good":          80,
+	"jayyid":        75,
+	"medium":        70,
+	"moderate":      70,
+	"average":       70,
+	"fair":          65,
+	"maqbul":        60,
+	"low":           50,
+	"poor":          40,
+	"very low":      30,
+}
+
+// ParseLegacyAccuracy converts an old free-text AccuracyLevel ("95", "95%",
+// "High", "B") to a 0-100 score. It returns false for values it can't map.
+func ParseLegacyAccuracy(level string) (float64, bool) {
+	level = strings.ToLower(strings.TrimSpace(level))
+	if level == "" {
+		return 0, false
+	}
+
+	if score, err := strconv.ParseFloat(strings.TrimSuffix(level, "%"), 64); err == nil {
+		if score >= 0 && score <= 1 && strings.Contains(level, ".") {
+			score *= 100
+		}
+		if score < 0 || score > 100 {
+			return 0, false
+		}
+		return score, true
+	}
+
+	if score, ok := legacyAccuracyLabels[level]; ok {
+		return score, true
+	}
+
+	// A letter grade maps to the middle of its band.
+	for i, band := range GradeBands {
+		if strings.EqualFold(band.Grade, level) {
+			upper := 100.0
+			if i > 0 {
+				upper = GradeBands[i-1].MinScore
+			}
+			return math.Round((band.MinScore + upper) / 2), true
+		}
+	}
+	return 0, false
+}
+
+// AfterFind fills in the derived AccuracyGrade and mushaf coverage whenever a
+// record is loaded.
+func (m *Memorize) AfterFind(tx *gorm.DB) error {
+	m.AccuracyGrade = Grade(m.AccuracyScore)
+	m.SetCoverage()
+	return nil
+}
@@ -1,11 +1,18 @@
 package model
 
 import (
+	"a21hc3NpZ25tZW50/quran"
+	"log/slog"
 	"time"
 
 	"gorm.io/gorm"
 )
 
+const (
+	RoleStudent = "student"
+	RoleTeacher = "teacher"
+)
+
 type User struct {
 	gorm.Model
 	Username   string `gorm:"uniqueIndex"`
@@ -13,9 +20,36 @@ type User struct {
 	Fullname   string
 	Desc       string
 	ProfilePic string
+	Role       string `gorm:"default:student"`
+	Timezone   string // IANA name, DefaultTimezone when empty
 	Memorizes  []Memorize
 }
 
+// DefaultTimezone is used for users that haven't set a timezone.
+const DefaultTimezone = "Asia/Jakarta"
+
+// LogValue keeps the password and profile out of logs that include the user.
+func (u User) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Uint64("id", uint64(u.ID)),
+		slog.String("username", u.Username),
+		slog.String("role", u.Role),
+	)
+}
+
+// Location returns the user's timezone.
+func (u User) Location() *time.Location {
+	name := u.Timezone
+	if name == "" {
+		name = DefaultTimezone
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
+
 type Memorize struct {
 	gorm.Model
 	UserID          uint
@@ -26,7 +60,318 @@ type Memorize struct {
 	DateCompleted   time.Time
 	ReviewFrequency string
 	LastReviewDate  time.Time
-	AccuracyLevel   string
+	AccuracyScore   *float64
+	AccuracyGrade   string `gorm:"-"`
 	NextReviewDate  time.Time
 	Notes           string
+
+	// Where the record is in the Madinah mushaf, filled in whenever it is
+	// loaded. Page can also be given instead of SurahName and AyahRange
+	// when creating a record.
+	Page string `gorm:"-"`
+	Juz  []int  `gorm:"-"`
+	Hizb []int  `gorm:"-"`
+	Rub  []int  `gorm:"-"`
+
+	// Derived from SurahName and AyahRange when the record is saved, so
+	// progress can be aggregated in SQL. FirstAyah and LastAyah are mushaf
+	// positions (see quran.Position.Index); all three are 0 when SurahName
+	// or AyahRange can't be parsed.
+	SurahNumber int `gorm:"index"`
+	FirstAyah   int
+	LastAyah    int
+}
+
+const (
+	MemorizeStatusCompleted  = "completed"
+	MemorizeStatusInProgress = "in_progress"
+	MemorizeStatusNotStarted = "not_started"
+)
+
+// IsCompleted reports whether the student has marked the passage as memorized.
+func (m Memorize) IsCompleted() bool {
+	return !m.DateCompleted.IsZero()
+}
+
+func (m Memorize) Status() string {
+	switch {
+	case m.IsCompleted():
+		return MemorizeStatusCompleted
+	case !m.DateStarted.IsZero():
+		return MemorizeStatusInProgress
+	default:
+		return MemorizeStatusNotStarted
+	}
+}
+
+// Span returns the memorized ayahs, or false when SurahName or AyahRange
+// can't be parsed.
+func (m Memorize) Span() (quran.Span, bool) {
+	surah, ok := quran.LookupSurah(m.SurahName)
+	if !ok {
+		return quran.Span{}, false
+	}
+	ayahs, err := quran.ParseAyahRange(m.AyahRange)
+	if err != nil || surah.Validate(ayahs) != nil {
+		return quran.Span{}, false
+	}
+	return surah.SpanOf(ayahs), true
+}
+
+// SetCoverage fills in Page, Juz, Hizb and Rub from the ayahs of the record.
+func (m *Memorize) SetCoverage() {
+	m.Page, m.Juz, m.Hizb, m.Rub = "", nil, nil, nil
+	span, ok := m.Span()
+	if !ok {
+		return
+	}
+	if coverage, ok := quran.CoverageOf(span); ok {
+		m.Page, m.Juz, m.Hizb, m.Rub = coverage.Pages.String(), coverage.Juz, coverage.Hizb, coverage.Rub
+	}
+}
+
+func (m *Memorize) BeforeSave(tx *gorm.DB) error {
+	m.SurahNumber, m.FirstAyah, m.LastAyah = 0, 0, 0
+	if span, ok := m.Span(); ok {
+		position, _ := quran.PositionAt(span.First)
+		m.SurahNumber, m.FirstAyah, m.LastAyah = position.Surah, span.First, span.Last
+	}
+	return nil
+}
+
+// Group is a teacher's class or halaqah that assignments can be given to.
+type Group struct {
+	gorm.Model
+	Name      string
+	TeacherID uint
+	Members   []GroupMember
+}
+
+type GroupMember struct {
+	gorm.Model
+	GroupID uint
+	UserID  uint
+}
+
+const (
+	AssignmentStatusAssigned   = "assigned"
+	AssignmentStatusInProgress = "in_progress"
+	AssignmentStatusCompleted  = "completed"
+	AssignmentStatusOverdue    = "overdue"
+)
+
+// Assignment is a memorization target set by a teacher, e.g. Al-Mulk 1-15
+// due on Friday. Each student it is given to gets an AssignmentRecipient row
+// that tracks their own progress.
+type Assignment struct {
+	gorm.Model
+	TeacherID  uint
+	GroupID    *uint
+	SurahName  string
+	StartAyah  int
+	EndAyah    int
+	DueDate    time.Time
+	Notes      string
+	Recipients []AssignmentRecipient
+}
+
+type AssignmentRecipient struct {
+	gorm.Model
+	AssignmentID uint
+	UserID       uint
+	MemorizeID   *uint
+	Status       string
+	CompletedAt  time.Time
+}
+
+const (
+	MistakeForgottenWord = "forgotten_word"
+	MistakeTajweed       = "tajweed"
+	MistakeMakhraj       = "makhraj"
+	MistakeHarakat       = "harakat"
+)
+
+// Evaluation is a tasmi' session where a teacher listens to a student recite
+// a passage and marks the mistakes made in each ayah.
+type Evaluation struct {
+	gorm.Model
+	TeacherID   uint
+	StudentID   uint
+	MemorizeID  *uint
+	SurahName   string
+	StartAyah   int
+	EndAyah     int
+	EvaluatedAt time.Time
+	Score       float64
+	Notes       string
+	Mistakes    []EvaluationMistake
+}
+
+type EvaluationMistake struct {
+	gorm.Model
+	EvaluationID uint
+	Ayah         int
+	Type         string
+	Word         string
+	Note         string
+}
+
+const (
+	ActivityMemorized = "memorized"
+	ActivityReviewed  = "reviewed"
+)
+
+// Activity is a single memorization or review session. Memorize only keeps
+// the latest dates, so every change is also logged here for streaks and the
+// activity calendar.
+type Activity struct {
+	gorm.Model
+	UserID     uint `gorm:"index"`
+	MemorizeID *uint
+	Type       string
+	Ayahs      int
+	OccurredAt time.Time `gorm:"index"`
+}
+
+const (
+	GoalScopeSurah = "surah"
+	GoalScopeJuz   = "juz"
+	GoalScopeQuran = "quran"
+
+	GoalDirectionForward  = "forward"
+	GoalDirectionBackward = "backward"
+)
+
+// Goal is a target such as "finish Juz 30 by Ramadan". The plan spreads the
+// ayahs left in the scope evenly until TargetDate at DailyPace ayahs per day,
+// starting from PlanStartDate when BaselineAyahs were already memorized. When
+// the user falls behind the plan is recalculated from the current progress.
+type Goal struct {
+	gorm.Model
+	UserID      uint `gorm:"index"`
+	Title       string
+	Scope       string
+	ScopeNumber int
+	// Direction is the order of the surahs; backward starts from the last
+	// surah of the scope, as is usual for Juz 'Amma.
+	Direction      string
+	StartDate      time.Time
+	TargetDate     time.Time
+	DailyPace      int
+	PaceFixed      bool
+	PlanStartDate  time.Time
+	BaselineAyahs  int
+	Recalculations int
+}
+
+const (
+	RotationUnitAyah = "ayah"
+	RotationUnitPage = "page"
+	RotationUnitJuz  = "juz"
+)
+
+// Rotation is a user's murajaah cycle. Everything memorized is revised once
+// every Days days, or at DailyAmount units (ayahs, pages or juz) per day when
+// Days is zero. StartDate is the first day of the first cycle.
+type Rotation struct {
+	gorm.Model
+	UserID      uint `gorm:"uniqueIndex"`
+	Unit        string
+	Days        int
+	DailyAmount float64
+	StartDate   time.Time
+}
+
+const (
+	QuestionContinueAyah = "continue_ayah"
+	QuestionWhichSurah   = "which_surah"
+	QuestionAyahNumber   = "ayah_number"
+	QuestionMissingWord  = "missing_word"
+)
+
+// Quiz is a self-test generated from the ayahs a user has memorized. Score
+// is set once the answers are submitted.
+type Quiz struct {
+	gorm.Model
+	UserID      uint `gorm:"index"`
+	SubmittedAt *time.Time
+	Score       *float64
+	Questions   []QuizQuestion
+}
+
+// QuizQuestion asks about one ayah of a memorize record. Prompt is the ayah
+// text the question is about and Options, when the question is multiple
+// choice, are separated by newlines.
+type QuizQuestion struct {
+	gorm.Model
+	QuizID     uint `gorm:"index"`
+	MemorizeID uint
+	Type       string
+	Question   string
+	Prompt     string
+	Options    string
+	SurahName  string
+	Ayah       int
+	Answer     string
+	Response   string
+	Correct    *bool
+}
+
+// Recording is an audio recording of a memorize record. Duration is in
+// seconds; it is nil for recordings uploaded before it was required.
+type Recording struct {
+	gorm.Model
+	MemorizeID  uint `gorm:"index"`
+	UserID      uint `gorm:"index"`
+	FileName    string
+	ContentType string
+	Size        int64
+	Duration    *float64
+	StorageKey  string `json:"-"`
+}
+
+// Comment is feedback on a memorize record, or on one of its recordings.
+// Replies point to the comment they answer with ParentID. Timestamp is a
+// position in seconds within the recording and Ayah an ayah of the record.
+type Comment struct {
+	gorm.Model
+	MemorizeID  uint `gorm:"index"`
+	RecordingID *uint
+	ParentID    *uint
+	AuthorID    uint
+	Body        string
+	Timestamp   *float64
+	Ayah        *int
+	EditedAt    *time.Time
+	Replies     []Comment `gorm:"-"`
+	Unread      bool      `gorm:"-"`
+}
+
+// CommentRead is when a user last read the comments on a memorize record,
+// or only those on one of its recordings when RecordingID isn't zero.
+type CommentRead struct {
+	gorm.Model
+	UserID      uint `gorm:"uniqueIndex:idx_comment_read_thread"`
+	MemorizeID  uint `gorm:"uniqueIndex:idx_comment_read_thread"`
+	RecordingID uint `gorm:"uniqueIndex:idx_comment_read_thread;not null;default:0"`
+	LastReadAt  time.Time
+}
+
+// Reading is a tilawah session, from the start ayah to the end ayah in mushaf
+// order. A reading that goes past An-Nas wraps around to Al-Fatiha. Duration
+// is in minutes. Khatam isn't stored; it is worked out from all of the user's
+// readings.
+type Reading struct {
+	gorm.Model
+	UserID     uint `gorm:"index"`
+	StartSurah int
+	StartAyah  int
+	EndSurah   int
+	EndAyah    int
+	Ayahs      int
+	Pages      int
+	Duration   int
+	Date       time.Time `gorm:"index"`
+	Khatam     bool      `gorm:"-"`
+	Notes      string
 }
@@ -0,0 +1,77 @@
+// Command gentext writes the complete Tanzil text that is embedded in the
+// quran package. It is run by go generate in the quran package:
+//
+//	go generate ./quran
+//
+// The text is downloaded from Tanzil, or read from a file with -from, and
+// only written when it has every ayah of the mushaf.
+package main
+
+import (
+	"a21hc3NpZ25tZW50/quran"
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// tanzilURL is the "Simple Clean" text, one "sura|aya|text" line per ayah
+// followed by the Tanzil license notice.
+const tanzilURL = "https://tanzil.net/pub/download/index.php?quranType=simple-clean&outType=txt-2&marks=true&sajdah=true&tatweel=true&agree=true"
+
+func main() {
+	out := flag.String("out", "data/quran-simple-clean.txt", "file to write")
+	from := flag.String("from", "", "Tanzil text file to use instead of downloading it")
+	flag.Parse()
+
+	text, err := readText(*from)
+	if err != nil {
+		log.Fatal(err)
+	}
+	corpus, err := quran.LoadTanzil(bytes.NewReader(text))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if !corpus.Complete() {
+		log.Fatalf("the text has %d of %d ayahs", corpus.Len(), quran.TotalAyahs)
+	}
+
+	// Write a temporary file first so a failure leaves the old text.
+	tmp, err := os.CreateTemp(filepath.Dir(*out), ".gentext-*")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(text); err != nil {
+		log.Fatal(err)
+	}
+	if err := tmp.Close(); err != nil {
+		log.Fatal(err)
+	}
+	if err := os.Rename(tmp.Name(), *out); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("wrote %d ayahs to %s\n", corpus.Len(), *out)
+}
+
+func readText(from string) ([]byte, error) {
+	if from != "" {
+		return os.ReadFile(from)
+	}
+
+	client := &http.Client{Timeout: time.Minute}
+	resp, err := client.Get(tanzilURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("downloading the Quran text: %s", resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
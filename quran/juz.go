@@ -0,0 +1,20 @@
+package quran
+
+// juzStarts is the first ayah of each of the 30 juz.
+var juzStarts = []Position{
+	{1, 1}, {2, 142}, {2, 253}, {3, 93}, {4, 24}, {4, 148}, {5, 82}, {6, 111}, {7, 88}, {8, 41},
+	{9, 93}, {11, 6}, {12, 53}, {15, 1}, {17, 1}, {18, 75}, {21, 1}, {23, 1}, {25, 21}, {27, 56},
+	{29, 46}, {33, 31}, {36, 28}, {39, 32}, {41, 47}, {46, 1}, {51, 31}, {58, 1}, {67, 1}, {78, 1},
+}
+
+const JuzCount = 30
+
+// JuzSpan returns the ayahs of juz number (1-30).
+func JuzSpan(number int) (Span, bool) {
+	return startsSpan(juzStarts, number)
+}
+
+// JuzOf returns the juz an ayah is in.
+func JuzOf(p Position) int {
+	return startsOf(juzStarts, p.Index())
+}
@@ -0,0 +1,73 @@
+package quran
+
+// Position identifies a single ayah.
+type Position struct {
+	Surah int
+	Ayah  int
+}
+
+// surahOffsets[n] is the number of ayahs before surah n+1.
+var surahOffsets = func() []int {
+	offsets := make([]int, len(Surahs)+1)
+	for i, s := range Surahs {
+		offsets[i+1] = offsets[i] + s.AyahCount
+	}
+	return offsets
+}()
+
+// Index returns the position of the ayah in the whole mushaf, from 1 (Al-Fatiha
+// 1) to TotalAyahs (An-Nas 6). It returns 0 for an ayah that doesn't exist.
+func (p Position) Index() int {
+	surah, ok := SurahByNumber(p.Surah)
+	if !ok || p.Ayah < 1 || p.Ayah > surah.AyahCount {
+		return 0
+	}
+	return surahOffsets[p.Surah-1] + p.Ayah
+}
+
+// PositionAt is the inverse of Position.Index.
+func PositionAt(index int) (Position, bool) {
+	if index < 1 || index > TotalAyahs {
+		return Position{}, false
+	}
+	surah := 1
+	for surahOffsets[surah] < index {
+		surah++
+	}
+	return Position{Surah: surah, Ayah: index - surahOffsets[surah-1]}, true
+}
+
+// Span is an inclusive range of ayahs by mushaf index, and may cross surahs.
+type Span struct {
+	First int
+	Last  int
+}
+
+func (s Span) Len() int {
+	if s.Last < s.First {
+		return 0
+	}
+	return s.Last - s.First + 1
+}
+
+// Intersect returns the number of ayahs s and other have in common.
+func (s Span) Intersect(other Span) int {
+	first, last := s.First, s.Last
+	if other.First > first {
+		first = other.First
+	}
+	if other.Last < last {
+		last = other.Last
+	}
+	return Span{first, last}.Len()
+}
+
+// Span returns the whole surah as a Span.
+func (s Surah) Span() Span {
+	return Span{First: surahOffsets[s.Number-1] + 1, Last: surahOffsets[s.Number]}
+}
+
+// SpanOf converts an ayah range of a surah to a Span.
+func (s Surah) SpanOf(r AyahRange) Span {
+	return Span{First: surahOffsets[s.Number-1] + r.Start, Last: surahOffsets[s.Number-1] + r.End}
+}
@@ -0,0 +1,175 @@
+package quran
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// TotalAyahs is the number of ayahs in the whole Quran (Hafs 'an 'Asim count).
+const TotalAyahs = 6236
+
+type Surah struct {
+	Number    int
+	Name      string
+	AyahCount int
+}
+
+var Surahs = []Surah{
+	{1, "Al-Fatiha", 7}, {2, "Al-Baqarah", 286}, {3, "Ali 'Imran", 200}, {4, "An-Nisa", 176},
+	{5, "Al-Ma'idah", 120}, {6, "Al-An'am", 165}, {7, "Al-A'raf", 206}, {8, "Al-Anfal", 75},
+	{9, "At-Tawbah", 129}, {10, "Yunus", 109}, {11, "Hud", 123}, {12, "Yusuf", 111},
+	{13, "Ar-Ra'd", 43}, {14, "Ibrahim", 52}, {15, "Al-Hijr", 99}, {16, "An-Nahl", 128},
+	{17, "Al-Isra", 111}, {18, "Al-Kahf", 110}, {19, "Maryam", 98}, {20, "Taha", 135},
+	{21, "Al-Anbiya", 112}, {22, "Al-Hajj", 78}, {23, "Al-Mu'minun", 118}, {24, "An-Nur", 64},
+	{25, "Al-Furqan", 77}, {26, "Ash-Shu'ara", 227}, {27, "An-Naml", 93}, {28, "Al-Qasas", 88},
+	{29, "Al-'Ankabut", 69}, {30, "Ar-Rum", 60}, {31, "Luqman", 34}, {32, "As-Sajdah", 30},
+	{33, "Al-Ahzab", 73}, {34, "Saba", 54}, {35, "Fatir", 45}, {36, "Ya-Sin", 83},
+	{37, "As-Saffat", 182}, {38, "Sad", 88}, {39, "Az-Zumar", 75}, {40, "Ghafir", 85},
+	{41, "Fussilat", 54}, {42, "Ash-Shura", 53}, {43, "Az-Zukhruf", 89}, {44, "Ad-Dukhan", 59},
+	{45, "Al-Jathiyah", 37}, {46, "Al-Ahqaf", 35}, {47, "Muhammad", 38}, {48, "Al-Fath", 29},
+	{49, "Al-Hujurat", 18}, {50, "Qaf", 45}, {51, "Adh-Dhariyat", 60}, {52, "At-Tur", 49},
+	{53, "An-Najm", 62}, {54, "Al-Qamar", 55}, {55, "Ar-Rahman", 78}, {56, "Al-Waqi'ah", 96},
+	{57, "Al-Hadid", 29}, {58, "Al-Mujadila", 22}, {59, "Al-Hashr", 24}, {60, "Al-Mumtahanah", 13},
+	{61, "As-Saf", 14}, {62, "Al-Jumu'ah", 11}, {63, "Al-Munafiqun", 11}, {64, "At-Taghabun", 18},
+	{65, "At-Talaq", 12}, {66, "At-Tahrim", 12}, {67, "Al-Mulk", 30}, {68, "Al-Qalam", 52},
+	{69, "Al-Haqqah", 52}, {70, "Al-Ma'arij", 44}, {71, "Nuh", 28}, {72, "Al-Jinn", 28},
+	{73, "Al-Muzzammil", 20}, {74, "Al-Muddaththir", 56}, {75, "Al-Qiyamah", 40}, {76, "Al-Insan", 31},
+	{77, "Al-Mursalat", 50}, {78, "An-Naba", 40}, {79, "An-Nazi'at", 46}, {80, "'Abasa", 42},
+	{81, "At-Takwir", 29}, {82, "Al-Infitar", 19}, {83, "Al-Mutaffifin", 36}, {84, "Al-Inshiqaq", 25},
+	{85, "Al-Buruj", 22}, {86, "At-Tariq", 17}, {87, "Al-A'la", 19}, {88, "Al-Ghashiyah", 26},
+	{89, "Al-Fajr", 30}, {90, "Al-Balad", 20}, {91, "Ash-Shams", 15}, {92, "Al-Layl", 21},
+	{93, "Ad-Duha", 11}, {94, "Ash-Sharh", 8}, {95, "At-Tin", 8}, {96, "Al-'Alaq", 19},
+	{97, "Al-Qadr", 5}, {98, "Al-Bayyinah", 8}, {99, "Az-Zalzalah", 8}, {100, "Al-'Adiyat", 11},
+	{101, "Al-Qari'ah", 11}, {102, "At-Takathur", 8}, {103, "Al-'Asr", 3}, {104, "Al-Humazah", 9},
+	{105, "Al-Fil", 5}, {106, "Quraysh", 4}, {107, "Al-Ma'un", 7}, {108, "Al-Kawthar", 3},
+	{109, "Al-Kafirun", 6}, {110, "An-Nasr", 3}, {111, "Al-Masad", 5}, {112, "Al-Ikhlas", 4},
+	{113, "Al-Falaq", 5}, {114, "An-Nas", 6},
+}
+
+// Common alternative spellings that don't reduce to the canonical name.
+var aliases = map[string]int{
+	"fatihah": 1, "alfatihah": 1, "imran": 3, "aliimran": 3, "alimran": 3, "nisaa": 4,
+	"maidah": 5, "tawba": 9, "taubah": 9, "bara'ah": 9, "baraah": 9, "israa": 17,
+	"banuisrail": 17, "kahfi": 18, "thaha": 20, "anbiyaa": 21, "muminun": 23,
+	"yasin": 36, "yaasiin": 36, "mumin": 40, "fushshilat": 41, "hamimsajdah": 41,
+	"waqiah": 56, "jumuah": 62, "tabarak": 67, "nabaa": 78, "amma": 78,
+	"dahr": 76, "insyirah": 94, "inshirah": 94, "alamnashrah": 94, "zilzal": 99,
+	"zalzalah": 99, "quraish": 106, "kautsar": 108, "kauthar": 108, "lahab": 111,
+	"ikhlash": 112,
+}
+
+var byKey = func() map[string]int {
+	keys := make(map[string]int, len(Surahs)*2+len(aliases))
+	for _, s := range Surahs {
+		keys[normalizeName(s.Name)] = s.Number
+		keys[normalizeName(stripArticle(s.Name))] = s.Number
+	}
+	for alias, number := range aliases {
+		keys[normalizeName(alias)] = number
+	}
+	return keys
+}()
+
+var articlePattern = regexp.MustCompile(`^(?i)(al|an|ar|as|ash|at|ath|ad|adh|az)[-\s]+`)
+
+func stripArticle(name string) string {
+	return articlePattern.ReplaceAllString(name, "")
+}
+
+func normalizeName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// LookupSurah resolves a surah by its number or transliterated name. Matching
+// ignores case, punctuation and the Arabic article, so "Al-Fatiha",
+// "al fatihah" and "1" all resolve to the same surah.
+func LookupSurah(name string) (Surah, bool) {
+	name = strings.TrimSpace(name)
+	if n, err := strconv.Atoi(name); err == nil {
+		return SurahByNumber(n)
+	}
+
+	for _, key := range []string{normalizeName(name), normalizeName(stripArticle(name))} {
+		if number, ok := byKey[key]; ok {
+			return Surahs[number-1], true
+		}
+	}
+	return Surah{}, false
+}
+
+func SurahByNumber(number int) (Surah, bool) {
+	if number < 1 || number > len(Surahs) {
+		return Surah{}, false
+	}
+	return Surahs[number-1], true
+}
+
+// AyahRange is an inclusive range of ayahs inside a single surah.
+type AyahRange struct {
+	Start int
+	End   int
+}
+
+func (r AyahRange) Len() int {
+	return r.End - r.Start + 1
+}
+
+func (r AyahRange) Contains(other AyahRange) bool {
+	return r.Start <= other.Start && other.End <= r.End
+}
+
+func (r AyahRange) Overlaps(other AyahRange) bool {
+	return r.Start <= other.End && other.Start <= r.End
+}
+
+func (r AyahRange) String() string {
+	if r.Start == r.End {
+		return strconv.Itoa(r.Start)
+	}
+	return fmt.Sprintf("%d-%d", r.Start, r.End)
+}
+
+var ErrInvalidAyahRange = errors.New("invalid ayah range")
+
+// ParseAyahRange parses the "start-end" (or single "n") format used by
+// Memorize.AyahRange.
+func ParseAyahRange(s string) (AyahRange, error) {
+	parts := strings.Split(strings.TrimSpace(s), "-")
+	if len(parts) > 2 {
+		return AyahRange{}, ErrInvalidAyahRange
+	}
+
+	start, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return AyahRange{}, ErrInvalidAyahRange
+	}
+	end := start
+	if len(parts) == 2 {
+		end, err = strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return AyahRange{}, ErrInvalidAyahRange
+		}
+	}
+
+	if start < 1 || end < start {
+		return AyahRange{}, ErrInvalidAyahRange
+	}
+	return AyahRange{Start: start, End: end}, nil
+}
+
+// Validate checks that the range fits inside the surah.
+func (s Surah) Validate(r AyahRange) error {
+	if r.Start < 1 || r.End < r.Start || r.End > s.AyahCount {
+		return fmt.Errorf("%w: %s has %d ayahs", ErrInvalidAyahRange, s.Name, s.AyahCount)
+	}
+	return nil
+}
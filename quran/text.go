@@ -0,0 +1,133 @@
+package quran
+
+import (
+	"bufio"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// The embedded text is written by go generate, which checks that it has
+// every ayah; see internal/gentext.
+//
+//go:generate go run ./internal/gentext -out data/quran-simple-clean.txt
+//go:embed data/quran-simple-clean.txt
+var embeddedText string
+
+var ErrTextUnavailable = errors.New("ayah text not available")
+
+// Verse is the text of a single ayah.
+type Verse struct {
+	Surah int
+	Ayah  int
+	Text  string
+}
+
+// Corpus holds the text of the ayahs by mushaf index. A corpus doesn't have
+// to be complete; ayahs it lacks are reported as unavailable.
+type Corpus struct {
+	text  []string
+	count int
+}
+
+// Text is the corpus used by the API. It starts out as the text embedded in
+// the binary and is replaced at startup when QURAN_TEXT_FILE is set.
+var Text = func() *Corpus {
+	corpus, err := LoadTanzil(strings.NewReader(embeddedText))
+	if err != nil {
+		panic(err)
+	}
+	return corpus
+}()
+
+// LoadTanzil reads text in the Tanzil "sura|aya|text" format. Empty lines
+// and lines starting with '#', like the license notice at the end of the
+// Tanzil files, are skipped.
+func LoadTanzil(r io.Reader) (*Corpus, error) {
+	corpus := &Corpus{text: make([]string, TotalAyahs+1)}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		row := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff"))
+		if row == "" || strings.HasPrefix(row, "#") {
+			continue
+		}
+
+		fields := strings.SplitN(row, "|", 3)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("line %d: expected sura|aya|text", line)
+		}
+		surah, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid sura %q", line, fields[0])
+		}
+		ayah, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid aya %q", line, fields[1])
+		}
+		index := Position{Surah: surah, Ayah: ayah}.Index()
+		if index == 0 {
+			return nil, fmt.Errorf("line %d: there is no ayah %d:%d", line, surah, ayah)
+		}
+		if corpus.text[index] == "" {
+			corpus.count++
+		}
+		corpus.text[index] = strings.TrimSpace(fields[2])
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return corpus, nil
+}
+
+// LoadTanzilFile loads a Tanzil text file from disk.
+func LoadTanzilFile(path string) (*Corpus, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return LoadTanzil(file)
+}
+
+// Len returns the number of ayahs with text.
+func (c *Corpus) Len() int {
+	return c.count
+}
+
+func (c *Corpus) Complete() bool {
+	return c.count == TotalAyahs
+}
+
+// Ayah returns the text of a single ayah.
+func (c *Corpus) Ayah(p Position) (Verse, error) {
+	index := p.Index()
+	if index == 0 || c.text[index] == "" {
+		return Verse{}, fmt.Errorf("%w: %d:%d", ErrTextUnavailable, p.Surah, p.Ayah)
+	}
+	return Verse{Surah: p.Surah, Ayah: p.Ayah, Text: c.text[index]}, nil
+}
+
+// Span returns the text of every ayah in the span. It fails if the corpus
+// lacks any of them.
+func (c *Corpus) Span(span Span) ([]Verse, error) {
+	verses := make([]Verse, 0, span.Len())
+	for index := span.First; index <= span.Last; index++ {
+		position, ok := PositionAt(index)
+		if !ok {
+			return nil, fmt.Errorf("%w: ayah %d of the mushaf", ErrTextUnavailable, index)
+		}
+		verse, err := c.Ayah(position)
+		if err != nil {
+			return nil, err
+		}
+		verses = append(verses, verse)
+	}
+	return verses, nil
+}
@@ -0,0 +1,154 @@
+package recitation
+
+// Op is the outcome of aligning one word.
+type Op string
+
+const (
+	Match      Op = "match"
+	Substitute Op = "substitute"
+	Missing    Op = "missing"
+	Extra      Op = "extra"
+)
+
+// Step is one step of an alignment. Expected is the index of the expected
+// word, or for Extra the index of the expected word it follows (-1 at the
+// start). Typed is the index of the typed word, -1 for Missing.
+type Step struct {
+	Op       Op
+	Expected int
+	Typed    int
+}
+
+// maxAlignCells is the largest cost matrix Align fills in whole. Longer
+// texts are split in two, as in Hirschberg's algorithm, until the parts fit,
+// so that memory grows with the length of the texts rather than with their
+// product.
+const maxAlignCells = 1 << 20
+
+// Align finds the alignment of typed against expected with the fewest
+// substituted, missing and extra words (their edit distance), preferring
+// matches when there are several.
+func Align(expected, typed []string) []Step {
+	return align(expected, typed, 0, 0, make([]Step, 0, len(expected)+len(typed)))
+}
+
+// align appends the alignment of expected and typed, which start at the
+// indexes first and firstTyped of the whole texts, to steps.
+func align(expected, typed []string, first, firstTyped int, steps []Step) []Step {
+	n, m := len(expected), len(typed)
+	if n < 2 || (n+1)*(m+1) <= maxAlignCells {
+		return alignAll(expected, typed, first, firstTyped, steps)
+	}
+
+	// Split typed where the best alignment of the first half of expected
+	// with the start of typed and of the second half with the rest costs
+	// the least.
+	half := n / 2
+	before := prefixCosts(expected[:half], typed)
+	after := suffixCosts(expected[half:], typed)
+	split := 0
+	for j := range before {
+		if before[j]+after[j] < before[split]+after[split] {
+			split = j
+		}
+	}
+	steps = align(expected[:half], typed[:split], first, firstTyped, steps)
+	return align(expected[half:], typed[split:], first+half, firstTyped+split, steps)
+}
+
+// prefixCosts returns the edit distance of expected and typed[:j] for every j.
+func prefixCosts(expected, typed []string) []int {
+	m := len(typed)
+	row, next := make([]int, m+1), make([]int, m+1)
+	for j := range row {
+		row[j] = j
+	}
+	for i := range expected {
+		next[0] = i + 1
+		for j := 1; j <= m; j++ {
+			best := row[j-1]
+			if expected[i] != typed[j-1] {
+				best++
+			}
+			next[j] = smallest(best, row[j]+1, next[j-1]+1)
+		}
+		row, next = next, row
+	}
+	return row
+}
+
+// suffixCosts returns the edit distance of expected and typed[j:] for every j.
+func suffixCosts(expected, typed []string) []int {
+	n, m := len(expected), len(typed)
+	row, next := make([]int, m+1), make([]int, m+1)
+	for j := range row {
+		row[j] = m - j
+	}
+	for i := n - 1; i >= 0; i-- {
+		next[m] = n - i
+		for j := m - 1; j >= 0; j-- {
+			best := row[j+1]
+			if expected[i] != typed[j] {
+				best++
+			}
+			next[j] = smallest(best, row[j]+1, next[j+1]+1)
+		}
+		row, next = next, row
+	}
+	return row
+}
+
+// alignAll aligns expected and typed with their whole cost matrix.
+func alignAll(expected, typed []string, first, firstTyped int, steps []Step) []Step {
+	n, m := len(expected), len(typed)
+	// cost[i][j] is the edit distance of expected[i:] and typed[j:].
+	cost := make([][]int, n+1)
+	for i := range cost {
+		cost[i] = make([]int, m+1)
+	}
+	for i := n; i >= 0; i-- {
+		for j := m; j >= 0; j-- {
+			switch {
+			case i == n:
+				cost[i][j] = m - j
+			case j == m:
+				cost[i][j] = n - i
+			default:
+				best := cost[i+1][j+1]
+				if expected[i] != typed[j] {
+					best++
+				}
+				best = smallest(best, cost[i+1][j]+1, cost[i][j+1]+1)
+				cost[i][j] = best
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && expected[i] == typed[j] && cost[i][j] == cost[i+1][j+1]:
+			steps = append(steps, Step{Op: Match, Expected: first + i, Typed: firstTyped + j})
+			i, j = i+1, j+1
+		case i < n && j < m && cost[i][j] == cost[i+1][j+1]+1:
+			steps = append(steps, Step{Op: Substitute, Expected: first + i, Typed: firstTyped + j})
+			i, j = i+1, j+1
+		case i < n && cost[i][j] == cost[i+1][j]+1:
+			steps = append(steps, Step{Op: Missing, Expected: first + i, Typed: -1})
+			i++
+		default:
+			steps = append(steps, Step{Op: Extra, Expected: first + i - 1, Typed: firstTyped + j})
+			j++
+		}
+	}
+	return steps
+}
+
+func smallest(first int, rest ...int) int {
+	for _, value := range rest {
+		if value < first {
+			first = value
+		}
+	}
+	return first
+}
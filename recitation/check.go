@@ -0,0 +1,108 @@
+package recitation
+
+import (
+	"a21hc3NpZ25tZW50/quran"
+	"math"
+	"strings"
+)
+
+// WordDiff is a word that was recited differently from the mushaf. Ayah and
+// Position (1-based, within the ayah) locate the expected word; for extra
+// words they locate the word the extra one follows.
+type WordDiff struct {
+	Ayah     int
+	Position int
+	Expected string `json:",omitempty"`
+	Typed    string `json:",omitempty"`
+}
+
+type Result struct {
+	Score         float64
+	ExpectedWords int
+	TypedWords    int
+	Matched       int
+	Missing       []WordDiff
+	Extra         []WordDiff
+	Substituted   []WordDiff
+}
+
+// word is a normalized word together with the text it was written as.
+type word struct {
+	normalized string
+	original   string
+	ayah       int
+	position   int
+}
+
+func split(text string, ayah int) []word {
+	var words []word
+	position := 0
+	for _, field := range strings.Fields(text) {
+		pieces := Words(field)
+		if len(pieces) > 0 {
+			// Waqf signs and ayah numbers on their own aren't words.
+			position++
+		}
+		for _, piece := range pieces {
+			words = append(words, word{normalized: piece, original: field, ayah: ayah, position: position})
+		}
+	}
+	return words
+}
+
+// Check compares typed text with the text of verses word by word. The score
+// is the share of expected words, less one for every missing, extra or
+// substituted word, as a percentage.
+func Check(verses []quran.Verse, typed string) Result {
+	var expected []word
+	for _, verse := range verses {
+		expected = append(expected, split(verse.Text, verse.Ayah)...)
+	}
+	recited := split(typed, 0)
+
+	result := Result{
+		ExpectedWords: len(expected),
+		TypedWords:    len(recited),
+		Missing:       []WordDiff{},
+		Extra:         []WordDiff{},
+		Substituted:   []WordDiff{},
+	}
+	expectedWords := make([]string, len(expected))
+	for i, w := range expected {
+		expectedWords[i] = w.normalized
+	}
+	typedWords := make([]string, len(recited))
+	for i, w := range recited {
+		typedWords[i] = w.normalized
+	}
+
+	for _, step := range Align(expectedWords, typedWords) {
+		switch step.Op {
+		case Match:
+			result.Matched++
+		case Substitute:
+			w := expected[step.Expected]
+			result.Substituted = append(result.Substituted, WordDiff{
+				Ayah: w.ayah, Position: w.position, Expected: w.original, Typed: recited[step.Typed].original,
+			})
+		case Missing:
+			w := expected[step.Expected]
+			result.Missing = append(result.Missing, WordDiff{Ayah: w.ayah, Position: w.position, Expected: w.original})
+		case Extra:
+			diff := WordDiff{Typed: recited[step.Typed].original}
+			if step.Expected >= 0 {
+				diff.Ayah, diff.Position = expected[step.Expected].ayah, expected[step.Expected].position
+			} else if len(expected) > 0 {
+				diff.Ayah = expected[0].ayah
+			}
+			result.Extra = append(result.Extra, diff)
+		}
+	}
+
+	if len(expected) > 0 {
+		mistakes := len(result.Missing) + len(result.Extra) + len(result.Substituted)
+		score := math.Max(0, 1-float64(mistakes)/float64(len(expected)))
+		result.Score = math.Round(score*10000) / 100
+	}
+	return result
+}
@@ -0,0 +1,61 @@
+package recitation
+
+import (
+	"strings"
+	"unicode"
+)
+
+// letters maps the variants of a letter that are commonly typed
+// interchangeably to a single form.
+var letters = map[rune]rune{
+	'أ': 'ا', 'إ': 'ا', 'آ': 'ا', 'ٱ': 'ا', 'ٲ': 'ا', 'ٳ': 'ا',
+	'ؤ': 'و',
+	'ئ': 'ي', 'ى': 'ي', 'ی': 'ي',
+	'ة': 'ه',
+	'ک': 'ك',
+}
+
+// isMark reports whether r is a diacritic, Quranic annotation sign or the
+// tatweel, none of which change which word was recited.
+func isMark(r rune) bool {
+	switch {
+	case r == 0x06DD: // end of ayah, separates words
+		return false
+	case r >= 0x064B && r <= 0x065F: // harakat, tanwin, shadda, sukun
+		return true
+	case r == 0x0670: // superscript alef
+		return true
+	case r >= 0x06D6 && r <= 0x06ED: // Quranic annotation signs
+		return true
+	case r == 0x0640: // tatweel
+		return true
+	}
+	return unicode.Is(unicode.Mn, r)
+}
+
+// Normalize strips diacritics, tatweel and punctuation from Arabic text,
+// folds alef, hamza, ya and ta marbuta variants and collapses whitespace, so
+// that text typed on any keyboard can be compared with the mushaf.
+func Normalize(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case isMark(r):
+			continue
+		case unicode.IsLetter(r):
+			if folded, ok := letters[r]; ok {
+				r = folded
+			}
+			b.WriteRune(r)
+		default:
+			// Digits, ayah markers and punctuation separate words.
+			b.WriteRune(' ')
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// Words splits normalized text into words.
+func Words(text string) []string {
+	return strings.Fields(Normalize(text))
+}
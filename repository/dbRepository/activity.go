@@ -0,0 +1,36 @@
+package dbRepository
+
+import (
+	"a21hc3NpZ25tZW50/model"
+	"time"
+)
+
+func (r *Repository) AddActivity(activity model.Activity) error {
+	return r.db.Create(&activity).Error
+}
+
+// Get the time of every Activity of a user, oldest first
+func (r *Repository) GetActivityTimes(userID uint) ([]time.Time, error) {
+	var times []time.Time
+	err := r.db.Model(&model.Activity{}).
+		Where("user_id = ?", userID).
+		Order("occurred_at").
+		Pluck("occurred_at", &times).Error
+	if err != nil {
+		return nil, err
+	}
+	return times, nil
+}
+
+// Get a user's Activities in [from, to). Times are compared in UTC, which is
+// how SQLite stores them.
+func (r *Repository) GetActivities(userID uint, from, to time.Time) ([]model.Activity, error) {
+	var activities []model.Activity
+	err := r.db.Where("user_id = ? AND occurred_at >= ? AND occurred_at < ?", userID, from.UTC(), to.UTC()).
+		Order("occurred_at").
+		Find(&activities).Error
+	if err != nil {
+		return nil, err
+	}
+	return activities, nil
+}
@@ -0,0 +1,113 @@
+package dbRepository
+
+import (
+	"a21hc3NpZ25tZW50/model"
+	"a21hc3NpZ25tZW50/repository"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+func (r *Repository) AddGroup(group model.Group) (uint, error) {
+	err := r.db.Create(&group).Error
+	if err != nil {
+		return 0, err
+	}
+	return group.ID, nil
+}
+
+// Get Group by ID together with its members
+func (r *Repository) GetGroupByID(groupID uint) (model.Group, error) {
+	var group model.Group
+	err := r.db.Preload("Members").First(&group, groupID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.Group{}, nil
+		}
+		return model.Group{}, err
+	}
+	return group, nil
+}
+
+func (r *Repository) GetGroupsByTeacher(teacherID uint) ([]model.Group, error) {
+	var groups []model.Group
+	err := r.db.Preload("Members").Where("teacher_id = ?", teacherID).Find(&groups).Error
+	if err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+func (r *Repository) AddGroupMember(member model.GroupMember) error {
+	return r.db.Create(&member).Error
+}
+
+func (r *Repository) DeleteGroupMember(groupID, userID uint) error {
+	result := r.db.Where("group_id = ? AND user_id = ?", groupID, userID).Delete(&model.GroupMember{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("group member %w", repository.ErrNotFound)
+	}
+	return nil
+}
+
+// Add Assignment together with its recipients
+func (r *Repository) AddAssignment(assignment model.Assignment) (uint, error) {
+	err := r.db.Create(&assignment).Error
+	if err != nil {
+		return 0, err
+	}
+	return assignment.ID, nil
+}
+
+func (r *Repository) GetAssignmentByID(assignmentID uint) (model.Assignment, error) {
+	var assignment model.Assignment
+	err := r.db.Preload("Recipients").First(&assignment, assignmentID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.Assignment{}, nil
+		}
+		return model.Assignment{}, err
+	}
+	return assignment, nil
+}
+
+func (r *Repository) GetAssignmentsByTeacher(teacherID uint) ([]model.Assignment, error) {
+	var assignments []model.Assignment
+	err := r.db.Preload("Recipients").
+		Where("teacher_id = ?", teacherID).
+		Order("due_date").
+		Find(&assignments).Error
+	if err != nil {
+		return nil, err
+	}
+	return assignments, nil
+}
+
+// Get all Assignments given to a student. Only the student's own recipient
+// row is loaded, so other students' progress is not exposed.
+func (r *Repository) GetAssignmentsByRecipient(userID uint) ([]model.Assignment, error) {
+	var assignments []model.Assignment
+	err := r.db.Preload("Recipients", "user_id = ?", userID).
+		Where("id IN (?)", r.db.Model(&model.AssignmentRecipient{}).Select("assignment_id").Where("user_id = ?", userID)).
+		Order("due_date").
+		Find(&assignments).Error
+	if err != nil {
+		return nil, err
+	}
+	return assignments, nil
+}
+
+func (r *Repository) UpdateAssignmentRecipient(recipient model.AssignmentRecipient) error {
+	return r.db.Save(&recipient).Error
+}
+
+// Detach a deleted Memorize record from any unfinished assignment it was linked to
+func (r *Repository) UnlinkMemorizeFromAssignments(memorizeID uint) error {
+	return r.db.Model(&model.AssignmentRecipient{}).
+		Where("memorize_id = ? AND status <> ?", memorizeID, model.AssignmentStatusCompleted).
+		Updates(map[string]interface{}{"memorize_id": nil, "status": model.AssignmentStatusAssigned}).Error
+}
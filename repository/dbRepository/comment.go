@@ -0,0 +1,116 @@
+package dbRepository
+
+import (
+	"a21hc3NpZ25tZW50/model"
+	"a21hc3NpZ25tZW50/repository"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func (r *Repository) AddComment(comment model.Comment) (model.Comment, error) {
+	err := r.db.Create(&comment).Error
+	if err != nil {
+		return model.Comment{}, err
+	}
+	return comment, nil
+}
+
+func (r *Repository) GetCommentByID(commentID uint) (model.Comment, error) {
+	var comment model.Comment
+	err := r.db.First(&comment, commentID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.Comment{}, nil
+		}
+		return model.Comment{}, err
+	}
+	return comment, nil
+}
+
+func (r *Repository) GetCommentsByMemorize(memorizeID uint) ([]model.Comment, error) {
+	var comments []model.Comment
+	err := r.db.Where("memorize_id = ?", memorizeID).Order("created_at, id").Find(&comments).Error
+	if err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+func (r *Repository) UpdateComment(comment model.Comment) error {
+	return r.db.Save(&comment).Error
+}
+
+// Delete a comment, moving its replies up to the comment it answered
+func (r *Repository) DeleteComment(comment model.Comment) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&model.Comment{}).
+			Where("parent_id = ?", comment.ID).
+			Update("parent_id", comment.ParentID).Error
+		if err != nil {
+			return err
+		}
+		return tx.Delete(&model.Comment{}, comment.ID).Error
+	})
+}
+
+func (r *Repository) DeleteCommentsByRecording(recordingID uint) error {
+	return r.db.Where("recording_id = ?", recordingID).Delete(&model.Comment{}).Error
+}
+
+// Get when the user last read the comments on a memorize record and on each
+// of its recordings
+func (r *Repository) GetCommentReads(userID, memorizeID uint) ([]model.CommentRead, error) {
+	var reads []model.CommentRead
+	err := r.db.Where("user_id = ? AND memorize_id = ?", userID, memorizeID).Find(&reads).Error
+	if err != nil {
+		return nil, err
+	}
+	return reads, nil
+}
+
+func (r *Repository) SetLastReadAt(userID, memorizeID, recordingID uint, readAt time.Time) error {
+	var read model.CommentRead
+	err := r.db.Where("user_id = ? AND memorize_id = ? AND recording_id = ?", userID, memorizeID, recordingID).
+		Attrs(model.CommentRead{UserID: userID, MemorizeID: memorizeID, RecordingID: recordingID}).
+		FirstOrInit(&read).Error
+	if err != nil {
+		return err
+	}
+	read.LastReadAt = readAt
+	return r.db.Save(&read).Error
+}
+
+// Get the unread comments by others on the memorize records of the user and
+// of the students in the user's groups. A comment on a recording is read
+// once the user has read the record's comments or the recording's.
+func (r *Repository) GetUnreadCounts(userID uint) ([]repository.UnreadCount, error) {
+	var counts []repository.UnreadCount
+	err := r.db.Raw(`
+		SELECT comments.memorize_id, COUNT(*) AS unread
+		FROM comments
+		JOIN memorizes ON memorizes.id = comments.memorize_id AND memorizes.deleted_at IS NULL
+		LEFT JOIN comment_reads ON comment_reads.memorize_id = comments.memorize_id
+			AND comment_reads.recording_id = 0
+			AND comment_reads.user_id = @user AND comment_reads.deleted_at IS NULL
+		LEFT JOIN comment_reads AS recording_reads ON recording_reads.memorize_id = comments.memorize_id
+			AND recording_reads.recording_id = comments.recording_id
+			AND recording_reads.user_id = @user AND recording_reads.deleted_at IS NULL
+		WHERE comments.deleted_at IS NULL AND comments.author_id <> @user
+			AND (comment_reads.last_read_at IS NULL OR comments.created_at > comment_reads.last_read_at)
+			AND (recording_reads.last_read_at IS NULL OR comments.created_at > recording_reads.last_read_at)
+			AND (memorizes.user_id = @user OR memorizes.user_id IN (
+				SELECT group_members.user_id FROM group_members
+				JOIN groups ON groups.id = group_members.group_id AND groups.deleted_at IS NULL
+				WHERE groups.teacher_id = @user AND group_members.deleted_at IS NULL
+			))
+		GROUP BY comments.memorize_id
+		ORDER BY comments.memorize_id`,
+		map[string]interface{}{"user": userID}).
+		Scan(&counts).Error
+	if err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
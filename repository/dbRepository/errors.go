@@ -0,0 +1,29 @@
+package dbRepository
+
+import (
+	"a21hc3NpZ25tZW50/repository"
+	"errors"
+	"fmt"
+
+	sqlite "github.com/glebarez/go-sqlite"
+	"github.com/jackc/pgconn"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// uniqueViolation is the SQLSTATE of a unique constraint violation in
+// PostgreSQL.
+const uniqueViolation = "23505"
+
+// conflictError wraps unique constraint violations in repository.ErrConflict
+// and returns other errors as they are.
+func conflictError(err error, what string) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		return fmt.Errorf("%w: %s", repository.ErrConflict, what)
+	}
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) && (sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE || sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY) {
+		return fmt.Errorf("%w: %s", repository.ErrConflict, what)
+	}
+	return err
+}
@@ -0,0 +1,81 @@
+package dbRepository
+
+import (
+	"a21hc3NpZ25tZW50/model"
+	"a21hc3NpZ25tZW50/repository"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// Add Evaluation together with its mistakes
+func (r *Repository) AddEvaluation(evaluation model.Evaluation) (uint, error) {
+	err := r.db.Create(&evaluation).Error
+	if err != nil {
+		return 0, err
+	}
+	return evaluation.ID, nil
+}
+
+func (r *Repository) GetEvaluationByID(evaluationID uint) (model.Evaluation, error) {
+	var evaluation model.Evaluation
+	err := r.db.Preload("Mistakes").First(&evaluation, evaluationID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.Evaluation{}, nil
+		}
+		return model.Evaluation{}, err
+	}
+	return evaluation, nil
+}
+
+func (r *Repository) GetEvaluationsByStudent(studentID uint) ([]model.Evaluation, error) {
+	var evaluations []model.Evaluation
+	err := r.db.Preload("Mistakes").
+		Where("student_id = ?", studentID).
+		Order("evaluated_at DESC").
+		Find(&evaluations).Error
+	if err != nil {
+		return nil, err
+	}
+	return evaluations, nil
+}
+
+func (r *Repository) GetEvaluationsByTeacher(teacherID uint) ([]model.Evaluation, error) {
+	var evaluations []model.Evaluation
+	err := r.db.Preload("Mistakes").
+		Where("teacher_id = ?", teacherID).
+		Order("evaluated_at DESC").
+		Find(&evaluations).Error
+	if err != nil {
+		return nil, err
+	}
+	return evaluations, nil
+}
+
+func (r *Repository) GetMistakeCountsByStudent(studentID uint) ([]repository.MistakeCount, error) {
+	var counts []repository.MistakeCount
+	err := r.db.Model(&model.EvaluationMistake{}).
+		Select("evaluations.surah_name, evaluation_mistakes.ayah, evaluation_mistakes.type, COUNT(*) AS count").
+		Joins("JOIN evaluations ON evaluations.id = evaluation_mistakes.evaluation_id AND evaluations.deleted_at IS NULL").
+		Where("evaluations.student_id = ?", studentID).
+		Group("evaluations.surah_name, evaluation_mistakes.ayah, evaluation_mistakes.type").
+		Scan(&counts).Error
+	if err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+// IsStudentOfTeacher reports whether the student belongs to any of the teacher's groups.
+func (r *Repository) IsStudentOfTeacher(teacherID, studentID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.GroupMember{}).
+		Joins("JOIN groups ON groups.id = group_members.group_id AND groups.deleted_at IS NULL").
+		Where("groups.teacher_id = ? AND group_members.user_id = ?", teacherID, studentID).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,54 @@
+package dbRepository
+
+import (
+	"a21hc3NpZ25tZW50/model"
+	"a21hc3NpZ25tZW50/repository"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+func (r *Repository) AddGoal(goal model.Goal) (uint, error) {
+	err := r.db.Create(&goal).Error
+	if err != nil {
+		return 0, err
+	}
+	return goal.ID, nil
+}
+
+func (r *Repository) GetGoalByID(goalID uint) (model.Goal, error) {
+	var goal model.Goal
+	err := r.db.First(&goal, goalID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.Goal{}, nil
+		}
+		return model.Goal{}, err
+	}
+	return goal, nil
+}
+
+func (r *Repository) GetGoalsByUser(userID uint) ([]model.Goal, error) {
+	var goals []model.Goal
+	err := r.db.Where("user_id = ?", userID).Order("target_date").Find(&goals).Error
+	if err != nil {
+		return nil, err
+	}
+	return goals, nil
+}
+
+func (r *Repository) UpdateGoal(goal model.Goal) error {
+	return r.db.Save(&goal).Error
+}
+
+func (r *Repository) DeleteGoal(goalID uint) error {
+	result := r.db.Delete(&model.Goal{}, goalID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("goal %w", repository.ErrNotFound)
+	}
+	return nil
+}
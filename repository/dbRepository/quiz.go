@@ -0,0 +1,52 @@
+package dbRepository
+
+import (
+	"a21hc3NpZ25tZW50/model"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// Add Quiz together with its questions
+func (r *Repository) AddQuiz(quiz model.Quiz) (uint, error) {
+	err := r.db.Create(&quiz).Error
+	if err != nil {
+		return 0, err
+	}
+	return quiz.ID, nil
+}
+
+func (r *Repository) GetQuizByID(quizID uint) (model.Quiz, error) {
+	var quiz model.Quiz
+	err := r.db.Preload("Questions", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).First(&quiz, quizID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.Quiz{}, nil
+		}
+		return model.Quiz{}, err
+	}
+	return quiz, nil
+}
+
+func (r *Repository) GetQuizzesByUser(userID uint) ([]model.Quiz, error) {
+	var quizzes []model.Quiz
+	err := r.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&quizzes).Error
+	if err != nil {
+		return nil, err
+	}
+	return quizzes, nil
+}
+
+// Save the submitted answers and the score of a quiz
+func (r *Repository) UpdateQuiz(quiz model.Quiz) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, question := range quiz.Questions {
+			if err := tx.Save(&question).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Omit("Questions").Save(&quiz).Error
+	})
+}
@@ -0,0 +1,68 @@
+package dbRepository
+
+import (
+	"a21hc3NpZ25tZW50/model"
+	"a21hc3NpZ25tZW50/repository"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func (r *Repository) AddReading(reading model.Reading) (model.Reading, error) {
+	if err := r.db.Create(&reading).Error; err != nil {
+		return model.Reading{}, err
+	}
+	return reading, nil
+}
+
+func (r *Repository) GetReadingByID(readingID uint) (model.Reading, error) {
+	var reading model.Reading
+	err := r.db.First(&reading, readingID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.Reading{}, nil
+		}
+		return model.Reading{}, err
+	}
+	return reading, nil
+}
+
+// Get every Reading of a user, oldest first
+func (r *Repository) GetReadingsByUser(userID uint) ([]model.Reading, error) {
+	var readings []model.Reading
+	err := r.db.Where("user_id = ?", userID).Order("date, id").Find(&readings).Error
+	if err != nil {
+		return nil, err
+	}
+	return readings, nil
+}
+
+// Get a user's Readings dated in [from, to), compared in UTC like
+// GetActivities
+func (r *Repository) GetReadings(userID uint, from, to time.Time) ([]model.Reading, error) {
+	var readings []model.Reading
+	err := r.db.Where("user_id = ? AND date >= ? AND date < ?", userID, from.UTC(), to.UTC()).
+		Order("date, id").
+		Find(&readings).Error
+	if err != nil {
+		return nil, err
+	}
+	return readings, nil
+}
+
+func (r *Repository) UpdateReading(reading model.Reading) error {
+	return r.db.Save(&reading).Error
+}
+
+func (r *Repository) DeleteReading(readingID uint) error {
+	result := r.db.Delete(&model.Reading{}, readingID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("reading %w", repository.ErrNotFound)
+	}
+	return nil
+}
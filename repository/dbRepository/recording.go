@@ -0,0 +1,41 @@
+package dbRepository
+
+import (
+	"a21hc3NpZ25tZW50/model"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+func (r *Repository) AddRecording(recording model.Recording) (model.Recording, error) {
+	err := r.db.Create(&recording).Error
+	if err != nil {
+		return model.Recording{}, err
+	}
+	return recording, nil
+}
+
+func (r *Repository) GetRecordingByID(recordingID uint) (model.Recording, error) {
+	var recording model.Recording
+	err := r.db.First(&recording, recordingID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.Recording{}, nil
+		}
+		return model.Recording{}, err
+	}
+	return recording, nil
+}
+
+func (r *Repository) GetRecordingsByMemorize(memorizeID uint) ([]model.Recording, error) {
+	var recordings []model.Recording
+	err := r.db.Where("memorize_id = ?", memorizeID).Order("created_at").Find(&recordings).Error
+	if err != nil {
+		return nil, err
+	}
+	return recordings, nil
+}
+
+func (r *Repository) DeleteRecording(recordingID uint) error {
+	return r.db.Delete(&model.Recording{}, recordingID).Error
+}
@@ -2,15 +2,20 @@ package dbRepository
 
 import (
 	"a21hc3NpZ25tZW50/model"
+	"a21hc3NpZ25tZW50/repository"
 	"errors"
+	"fmt"
 
 	"gorm.io/gorm"
 )
 
+// Repository stores everything with GORM.
 type Repository struct {
 	db *gorm.DB
 }
 
+var _ repository.Repository = (*Repository)(nil)
+
 func NewRepository(db *gorm.DB) *Repository {
 	return &Repository{db: db}
 }
@@ -18,7 +23,7 @@ func NewRepository(db *gorm.DB) *Repository {
 func (r *Repository) AddUser(user model.User) (string, error) {
 	err := r.db.Create(&user).Error
 	if err != nil {
-		return "", err
+		return "", conflictError(err, "users.username")
 	}
 	return user.Username, nil
 }
@@ -50,7 +55,7 @@ func (r *Repository) GetMemorizeByID(memorizeID uint) (model.Memorize, error) {
 	err := r.db.First(&memorize, memorizeID).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return model.Memorize{}, nil
+			return model.Memorize{}, fmt.Errorf("memorize record %w", repository.ErrNotFound)
 		}
 		return model.Memorize{}, err
 	}
@@ -64,7 +69,7 @@ func (r *Repository) DeleteMemorize(memorizeID uint) error {
 		return result.Error
 	}
 	if result.RowsAffected == 0 {
-		return errors.New("memorize record not found")
+		return fmt.Errorf("memorize record %w", repository.ErrNotFound)
 	}
 	return nil
 }
@@ -91,3 +96,35 @@ func (r *Repository) GetAllMemorizesByUser(username string) ([]model.Memorize, e
 func (r *Repository) UpdateMemorize(memorize model.Memorize) error {
 	return r.db.Save(&memorize).Error
 }
+
+func (r *Repository) SetUserRole(username, role string) error {
+	result := r.db.Model(&model.User{}).Where("username = ?", username).Update("role", role)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("user %w", repository.ErrNotFound)
+	}
+	return nil
+}
+
+func (r *Repository) GetUserByID(userID uint) (model.User, error) {
+	var user model.User
+	err := r.db.First(&user, userID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.User{}, nil
+		}
+		return model.User{}, err
+	}
+	return user, nil
+}
+
+func (r *Repository) GetMemorizesByUserID(userID uint) ([]model.Memorize, error) {
+	var memorizes []model.Memorize
+	err := r.db.Where("user_id = ?", userID).Find(&memorizes).Error
+	if err != nil {
+		return nil, err
+	}
+	return memorizes, nil
+}
@@ -0,0 +1,29 @@
+package dbRepository
+
+import (
+	"a21hc3NpZ25tZW50/model"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+func (r *Repository) GetRotationByUser(userID uint) (model.Rotation, error) {
+	var rotation model.Rotation
+	err := r.db.Where("user_id = ?", userID).First(&rotation).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.Rotation{}, nil
+		}
+		return model.Rotation{}, err
+	}
+	return rotation, nil
+}
+
+// Save the rotation, creating it on first use
+func (r *Repository) SaveRotation(rotation model.Rotation) (model.Rotation, error) {
+	err := r.db.Save(&rotation).Error
+	if err != nil {
+		return model.Rotation{}, conflictError(err, "rotations.user_id")
+	}
+	return rotation, nil
+}
@@ -0,0 +1,53 @@
+package dbRepository
+
+import (
+	"a21hc3NpZ25tZW50/model"
+	"a21hc3NpZ25tZW50/quran"
+	"a21hc3NpZ25tZW50/repository"
+	"time"
+)
+
+// Dates are stored as zero time.Time when they aren't set.
+var unsetDate = time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Count a user's Memorize records by status and average their accuracy
+func (r *Repository) GetMemorizeSummary(userID uint) (repository.MemorizeSummary, error) {
+	var summary repository.MemorizeSummary
+	err := r.db.Model(&model.Memorize{}).
+		Select(`COALESCE(SUM(CASE WHEN date_completed > @unset THEN 1 ELSE 0 END), 0) AS completed,
+			COALESCE(SUM(CASE WHEN date_completed <= @unset AND date_started > @unset THEN 1 ELSE 0 END), 0) AS in_progress,
+			COALESCE(SUM(CASE WHEN date_completed <= @unset AND date_started <= @unset THEN 1 ELSE 0 END), 0) AS not_started,
+			AVG(accuracy_score) AS average_accuracy`, map[string]interface{}{"unset": unsetDate}).
+		Where("user_id = ?", userID).
+		Scan(&summary).Error
+	return summary, err
+}
+
+// Get the ayahs a user has completed as non-overlapping spans, merging
+// overlapping and adjacent records in the database.
+func (r *Repository) GetMemorizedSpans(userID uint) ([]quran.Span, error) {
+	var spans []quran.Span
+	err := r.db.Raw(`
+		WITH spans AS (
+			SELECT first_ayah, last_ayah FROM memorizes
+			WHERE user_id = @user AND deleted_at IS NULL AND first_ayah > 0 AND date_completed > @unset
+		), marked AS (
+			SELECT first_ayah, last_ayah,
+				CASE WHEN first_ayah <= MAX(last_ayah) OVER (
+					ORDER BY first_ayah, last_ayah ROWS BETWEEN UNBOUNDED PRECEDING AND 1 PRECEDING
+				) + 1 THEN 0 ELSE 1 END AS starts_island
+			FROM spans
+		), islands AS (
+			SELECT first_ayah, last_ayah,
+				SUM(starts_island) OVER (ORDER BY first_ayah, last_ayah ROWS UNBOUNDED PRECEDING) AS island
+			FROM marked
+		)
+		SELECT MIN(first_ayah) AS first, MAX(last_ayah) AS last
+		FROM islands GROUP BY island ORDER BY first`,
+		map[string]interface{}{"user": userID, "unset": unsetDate}).
+		Scan(&spans).Error
+	if err != nil {
+		return nil, err
+	}
+	return spans, nil
+}
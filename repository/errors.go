@@ -0,0 +1,10 @@
+package repository
+
+import "errors"
+
+// Errors the repositories return, wrapped with what wasn't found or what it
+// clashed with.
+var (
+	ErrNotFound = errors.New("not found")
+	ErrConflict = errors.New("already exists")
+)
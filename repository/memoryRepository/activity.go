@@ -0,0 +1,47 @@
+package memoryRepository
+
+import (
+	"a21hc3NpZ25tZW50/model"
+	"sort"
+	"time"
+)
+
+func (r *Repository) AddActivity(activity model.Activity) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.create("activities", &activity.Model, time.Now())
+	r.activities = append(r.activities, activity)
+	return nil
+}
+
+// activitiesWhere returns the matching activities, oldest first.
+func (r *Repository) activitiesWhere(match func(model.Activity) bool) []model.Activity {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var activities []model.Activity
+	for _, activity := range r.activities {
+		if match(activity) {
+			activities = append(activities, activity)
+		}
+	}
+	sort.SliceStable(activities, func(i, j int) bool {
+		return activities[i].OccurredAt.Before(activities[j].OccurredAt)
+	})
+	return activities
+}
+
+func (r *Repository) GetActivityTimes(userID uint) ([]time.Time, error) {
+	var times []time.Time
+	for _, activity := range r.activitiesWhere(func(activity model.Activity) bool { return activity.UserID == userID }) {
+		times = append(times, activity.OccurredAt)
+	}
+	return times, nil
+}
+
+func (r *Repository) GetActivities(userID uint, from, to time.Time) ([]model.Activity, error) {
+	return r.activitiesWhere(func(activity model.Activity) bool {
+		return activity.UserID == userID && !activity.OccurredAt.Before(from) && activity.OccurredAt.Before(to)
+	}), nil
+}
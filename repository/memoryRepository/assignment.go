@@ -0,0 +1,209 @@
+package memoryRepository
+
+import (
+	"a21hc3NpZ25tZW50/model"
+	"a21hc3NpZ25tZW50/repository"
+	"fmt"
+	"sort"
+	"time"
+)
+
+func (r *Repository) AddGroup(group model.Group) (uint, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	r.create("groups", &group.Model, now)
+	for _, member := range group.Members {
+		member.GroupID = group.ID
+		r.create("group_members", &member.Model, now)
+		r.groupMembers = append(r.groupMembers, member)
+	}
+	group.Members = nil
+	r.groups = append(r.groups, group)
+	return group.ID, nil
+}
+
+func (r *Repository) withMembers(group model.Group) model.Group {
+	group.Members = nil
+	for _, member := range r.groupMembers {
+		if member.GroupID == group.ID {
+			group.Members = append(group.Members, member)
+		}
+	}
+	return group
+}
+
+func (r *Repository) GetGroupByID(groupID uint) (model.Group, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, group := range r.groups {
+		if group.ID == groupID {
+			return r.withMembers(group), nil
+		}
+	}
+	return model.Group{}, nil
+}
+
+func (r *Repository) GetGroupsByTeacher(teacherID uint) ([]model.Group, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var groups []model.Group
+	for _, group := range r.groups {
+		if group.TeacherID == teacherID {
+			groups = append(groups, r.withMembers(group))
+		}
+	}
+	return groups, nil
+}
+
+func (r *Repository) AddGroupMember(member model.GroupMember) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.create("group_members", &member.Model, time.Now())
+	r.groupMembers = append(r.groupMembers, member)
+	return nil
+}
+
+func (r *Repository) DeleteGroupMember(groupID, userID uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	kept := r.groupMembers[:0]
+	for _, member := range r.groupMembers {
+		if member.GroupID != groupID || member.UserID != userID {
+			kept = append(kept, member)
+		}
+	}
+	deleted := len(r.groupMembers) - len(kept)
+	r.groupMembers = kept
+	if deleted == 0 {
+		return fmt.Errorf("group member %w", repository.ErrNotFound)
+	}
+	return nil
+}
+
+func (r *Repository) IsStudentOfTeacher(teacherID, studentID uint) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, group := range r.groups {
+		if group.TeacherID != teacherID {
+			continue
+		}
+		for _, member := range r.groupMembers {
+			if member.GroupID == group.ID && member.UserID == studentID {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+func (r *Repository) AddAssignment(assignment model.Assignment) (uint, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	r.create("assignments", &assignment.Model, now)
+	for _, recipient := range assignment.Recipients {
+		recipient.AssignmentID = assignment.ID
+		r.create("assignment_recipients", &recipient.Model, now)
+		r.recipients = append(r.recipients, recipient)
+	}
+	assignment.Recipients = nil
+	r.assignments = append(r.assignments, assignment)
+	return assignment.ID, nil
+}
+
+// withRecipients attaches the recipients of an assignment, only that of
+// userID when it isn't zero.
+func (r *Repository) withRecipients(assignment model.Assignment, userID uint) model.Assignment {
+	assignment.Recipients = nil
+	for _, recipient := range r.recipients {
+		if recipient.AssignmentID == assignment.ID && (userID == 0 || recipient.UserID == userID) {
+			assignment.Recipients = append(assignment.Recipients, recipient)
+		}
+	}
+	return assignment
+}
+
+func sortByDueDate(assignments []model.Assignment) {
+	sort.SliceStable(assignments, func(i, j int) bool {
+		return assignments[i].DueDate.Before(assignments[j].DueDate)
+	})
+}
+
+func (r *Repository) GetAssignmentByID(assignmentID uint) (model.Assignment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, assignment := range r.assignments {
+		if assignment.ID == assignmentID {
+			return r.withRecipients(assignment, 0), nil
+		}
+	}
+	return model.Assignment{}, nil
+}
+
+func (r *Repository) GetAssignmentsByTeacher(teacherID uint) ([]model.Assignment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var assignments []model.Assignment
+	for _, assignment := range r.assignments {
+		if assignment.TeacherID == teacherID {
+			assignments = append(assignments, r.withRecipients(assignment, 0))
+		}
+	}
+	sortByDueDate(assignments)
+	return assignments, nil
+}
+
+func (r *Repository) GetAssignmentsByRecipient(userID uint) ([]model.Assignment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var assignments []model.Assignment
+	for _, assignment := range r.assignments {
+		assignment = r.withRecipients(assignment, userID)
+		if len(assignment.Recipients) > 0 {
+			assignments = append(assignments, assignment)
+		}
+	}
+	sortByDueDate(assignments)
+	return assignments, nil
+}
+
+func (r *Repository) UpdateAssignmentRecipient(recipient model.AssignmentRecipient) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, existing := range r.recipients {
+		if existing.ID == recipient.ID {
+			recipient.UpdatedAt = time.Now()
+			r.recipients[i] = recipient
+			return nil
+		}
+	}
+	r.create("assignment_recipients", &recipient.Model, time.Now())
+	r.recipients = append(r.recipients, recipient)
+	return nil
+}
+
+func (r *Repository) UnlinkMemorizeFromAssignments(memorizeID uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, recipient := range r.recipients {
+		if recipient.MemorizeID != nil && *recipient.MemorizeID == memorizeID && recipient.Status != model.AssignmentStatusCompleted {
+			r.recipients[i].MemorizeID = nil
+			r.recipients[i].Status = model.AssignmentStatusAssigned
+			r.recipients[i].UpdatedAt = time.Now()
+		}
+	}
+	return nil
+}
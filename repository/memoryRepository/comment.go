@@ -0,0 +1,179 @@
+package memoryRepository
+
+import (
+	"a21hc3NpZ25tZW50/model"
+	"a21hc3NpZ25tZW50/repository"
+	"sort"
+	"time"
+)
+
+func (r *Repository) AddComment(comment model.Comment) (model.Comment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	comment.Replies, comment.Unread = nil, false
+	r.create("comments", &comment.Model, time.Now())
+	r.comments = append(r.comments, comment)
+	return comment, nil
+}
+
+func (r *Repository) GetCommentByID(commentID uint) (model.Comment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, comment := range r.comments {
+		if comment.ID == commentID {
+			return comment, nil
+		}
+	}
+	return model.Comment{}, nil
+}
+
+func (r *Repository) GetCommentsByMemorize(memorizeID uint) ([]model.Comment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var comments []model.Comment
+	for _, comment := range r.comments {
+		if comment.MemorizeID == memorizeID {
+			comments = append(comments, comment)
+		}
+	}
+	sort.SliceStable(comments, func(i, j int) bool { return comments[i].CreatedAt.Before(comments[j].CreatedAt) })
+	return comments, nil
+}
+
+func (r *Repository) UpdateComment(comment model.Comment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	comment.Replies, comment.Unread = nil, false
+	for i, existing := range r.comments {
+		if existing.ID == comment.ID {
+			comment.UpdatedAt = time.Now()
+			r.comments[i] = comment
+			return nil
+		}
+	}
+	r.create("comments", &comment.Model, time.Now())
+	r.comments = append(r.comments, comment)
+	return nil
+}
+
+func (r *Repository) DeleteComment(comment model.Comment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	kept := r.comments[:0]
+	for _, existing := range r.comments {
+		if existing.ID == comment.ID {
+			continue
+		}
+		if existing.ParentID != nil && *existing.ParentID == comment.ID {
+			existing.ParentID = comment.ParentID
+		}
+		kept = append(kept, existing)
+	}
+	r.comments = kept
+	return nil
+}
+
+func (r *Repository) DeleteCommentsByRecording(recordingID uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	kept := r.comments[:0]
+	for _, comment := range r.comments {
+		if comment.RecordingID == nil || *comment.RecordingID != recordingID {
+			kept = append(kept, comment)
+		}
+	}
+	r.comments = kept
+	return nil
+}
+
+// isRead tells whether the user has read the comment, on its memorize
+// record or on its recording.
+func (r *Repository) isRead(userID uint, comment model.Comment) bool {
+	for _, read := range r.commentReads {
+		if read.UserID != userID || read.MemorizeID != comment.MemorizeID || comment.CreatedAt.After(read.LastReadAt) {
+			continue
+		}
+		if read.RecordingID == 0 || (comment.RecordingID != nil && *comment.RecordingID == read.RecordingID) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Repository) GetCommentReads(userID, memorizeID uint) ([]model.CommentRead, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	reads := []model.CommentRead{}
+	for _, read := range r.commentReads {
+		if read.UserID == userID && read.MemorizeID == memorizeID {
+			reads = append(reads, read)
+		}
+	}
+	return reads, nil
+}
+
+func (r *Repository) SetLastReadAt(userID, memorizeID, recordingID uint, readAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, read := range r.commentReads {
+		if read.UserID == userID && read.MemorizeID == memorizeID && read.RecordingID == recordingID {
+			r.commentReads[i].LastReadAt = readAt
+			r.commentReads[i].UpdatedAt = time.Now()
+			return nil
+		}
+	}
+	read := model.CommentRead{UserID: userID, MemorizeID: memorizeID, RecordingID: recordingID, LastReadAt: readAt}
+	r.create("comment_reads", &read.Model, time.Now())
+	r.commentReads = append(r.commentReads, read)
+	return nil
+}
+
+func (r *Repository) GetUnreadCounts(userID uint) ([]repository.UnreadCount, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// The user's own records and those of the students in their groups
+	owners := map[uint]bool{userID: true}
+	for _, group := range r.groups {
+		if group.TeacherID != userID {
+			continue
+		}
+		for _, member := range r.groupMembers {
+			if member.GroupID == group.ID {
+				owners[member.UserID] = true
+			}
+		}
+	}
+	visible := map[uint]bool{}
+	for _, memorize := range r.memorizes {
+		if owners[memorize.UserID] {
+			visible[memorize.ID] = true
+		}
+	}
+
+	unread := map[uint]int{}
+	for _, comment := range r.comments {
+		if !visible[comment.MemorizeID] || comment.AuthorID == userID {
+			continue
+		}
+		if r.isRead(userID, comment) {
+			continue
+		}
+		unread[comment.MemorizeID]++
+	}
+
+	counts := []repository.UnreadCount{}
+	for memorizeID, count := range unread {
+		counts = append(counts, repository.UnreadCount{MemorizeID: memorizeID, Unread: count})
+	}
+	sort.Slice(counts, func(i, j int) bool { return counts[i].MemorizeID < counts[j].MemorizeID })
+	return counts, nil
+}
@@ -0,0 +1,105 @@
+package memoryRepository
+
+import (
+	"a21hc3NpZ25tZW50/model"
+	"a21hc3NpZ25tZW50/repository"
+	"sort"
+	"time"
+)
+
+func (r *Repository) AddEvaluation(evaluation model.Evaluation) (uint, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	r.create("evaluations", &evaluation.Model, now)
+	for _, mistake := range evaluation.Mistakes {
+		mistake.EvaluationID = evaluation.ID
+		r.create("evaluation_mistakes", &mistake.Model, now)
+		r.mistakes = append(r.mistakes, mistake)
+	}
+	evaluation.Mistakes = nil
+	r.evaluations = append(r.evaluations, evaluation)
+	return evaluation.ID, nil
+}
+
+func (r *Repository) withMistakes(evaluation model.Evaluation) model.Evaluation {
+	evaluation.Mistakes = nil
+	for _, mistake := range r.mistakes {
+		if mistake.EvaluationID == evaluation.ID {
+			evaluation.Mistakes = append(evaluation.Mistakes, mistake)
+		}
+	}
+	return evaluation
+}
+
+func (r *Repository) GetEvaluationByID(evaluationID uint) (model.Evaluation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, evaluation := range r.evaluations {
+		if evaluation.ID == evaluationID {
+			return r.withMistakes(evaluation), nil
+		}
+	}
+	return model.Evaluation{}, nil
+}
+
+// evaluationsWhere returns the matching evaluations, latest first.
+func (r *Repository) evaluationsWhere(match func(model.Evaluation) bool) []model.Evaluation {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var evaluations []model.Evaluation
+	for _, evaluation := range r.evaluations {
+		if match(evaluation) {
+			evaluations = append(evaluations, r.withMistakes(evaluation))
+		}
+	}
+	sort.SliceStable(evaluations, func(i, j int) bool {
+		return evaluations[i].EvaluatedAt.After(evaluations[j].EvaluatedAt)
+	})
+	return evaluations
+}
+
+func (r *Repository) GetEvaluationsByStudent(studentID uint) ([]model.Evaluation, error) {
+	return r.evaluationsWhere(func(evaluation model.Evaluation) bool { return evaluation.StudentID == studentID }), nil
+}
+
+func (r *Repository) GetEvaluationsByTeacher(teacherID uint) ([]model.Evaluation, error) {
+	return r.evaluationsWhere(func(evaluation model.Evaluation) bool { return evaluation.TeacherID == teacherID }), nil
+}
+
+func (r *Repository) GetMistakeCountsByStudent(studentID uint) ([]repository.MistakeCount, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	surahs := map[uint]string{}
+	for _, evaluation := range r.evaluations {
+		if evaluation.StudentID == studentID {
+			surahs[evaluation.ID] = evaluation.SurahName
+		}
+	}
+
+	type key struct {
+		surahName string
+		ayah      int
+		kind      string
+	}
+	index := map[key]int{}
+	var counts []repository.MistakeCount
+	for _, mistake := range r.mistakes {
+		surahName, ok := surahs[mistake.EvaluationID]
+		if !ok {
+			continue
+		}
+		k := key{surahName, mistake.Ayah, mistake.Type}
+		if i, ok := index[k]; ok {
+			counts[i].Count++
+			continue
+		}
+		index[k] = len(counts)
+		counts = append(counts, repository.MistakeCount{SurahName: surahName, Ayah: mistake.Ayah, Type: mistake.Type, Count: 1})
+	}
+	return counts, nil
+}
@@ -0,0 +1,73 @@
+package memoryRepository
+
+import (
+	"a21hc3NpZ25tZW50/model"
+	"a21hc3NpZ25tZW50/repository"
+	"fmt"
+	"sort"
+	"time"
+)
+
+func (r *Repository) AddGoal(goal model.Goal) (uint, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.create("goals", &goal.Model, time.Now())
+	r.goals = append(r.goals, goal)
+	return goal.ID, nil
+}
+
+func (r *Repository) GetGoalByID(goalID uint) (model.Goal, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, goal := range r.goals {
+		if goal.ID == goalID {
+			return goal, nil
+		}
+	}
+	return model.Goal{}, nil
+}
+
+func (r *Repository) GetGoalsByUser(userID uint) ([]model.Goal, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var goals []model.Goal
+	for _, goal := range r.goals {
+		if goal.UserID == userID {
+			goals = append(goals, goal)
+		}
+	}
+	sort.SliceStable(goals, func(i, j int) bool { return goals[i].TargetDate.Before(goals[j].TargetDate) })
+	return goals, nil
+}
+
+func (r *Repository) UpdateGoal(goal model.Goal) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, existing := range r.goals {
+		if existing.ID == goal.ID {
+			goal.UpdatedAt = time.Now()
+			r.goals[i] = goal
+			return nil
+		}
+	}
+	r.create("goals", &goal.Model, time.Now())
+	r.goals = append(r.goals, goal)
+	return nil
+}
+
+func (r *Repository) DeleteGoal(goalID uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, goal := range r.goals {
+		if goal.ID == goalID {
+			r.goals = append(r.goals[:i], r.goals[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("goal %w", repository.ErrNotFound)
+}
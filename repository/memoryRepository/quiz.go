@@ -0,0 +1,78 @@
+package memoryRepository
+
+import (
+	"a21hc3NpZ25tZW50/model"
+	"sort"
+	"time"
+)
+
+func (r *Repository) AddQuiz(quiz model.Quiz) (uint, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	r.create("quizzes", &quiz.Model, now)
+	for _, question := range quiz.Questions {
+		question.QuizID = quiz.ID
+		r.create("quiz_questions", &question.Model, now)
+		r.quizQuestions = append(r.quizQuestions, question)
+	}
+	quiz.Questions = nil
+	r.quizzes = append(r.quizzes, quiz)
+	return quiz.ID, nil
+}
+
+func (r *Repository) GetQuizByID(quizID uint) (model.Quiz, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, quiz := range r.quizzes {
+		if quiz.ID != quizID {
+			continue
+		}
+		for _, question := range r.quizQuestions {
+			if question.QuizID == quiz.ID {
+				quiz.Questions = append(quiz.Questions, question)
+			}
+		}
+		return quiz, nil
+	}
+	return model.Quiz{}, nil
+}
+
+func (r *Repository) GetQuizzesByUser(userID uint) ([]model.Quiz, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var quizzes []model.Quiz
+	for _, quiz := range r.quizzes {
+		if quiz.UserID == userID {
+			quizzes = append(quizzes, quiz)
+		}
+	}
+	sort.SliceStable(quizzes, func(i, j int) bool { return quizzes[i].CreatedAt.After(quizzes[j].CreatedAt) })
+	return quizzes, nil
+}
+
+func (r *Repository) UpdateQuiz(quiz model.Quiz) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for _, question := range quiz.Questions {
+		for i, existing := range r.quizQuestions {
+			if existing.ID == question.ID {
+				question.UpdatedAt = now
+				r.quizQuestions[i] = question
+			}
+		}
+	}
+	for i, existing := range r.quizzes {
+		if existing.ID == quiz.ID {
+			quiz.Questions = nil
+			quiz.UpdatedAt = now
+			r.quizzes[i] = quiz
+		}
+	}
+	return nil
+}
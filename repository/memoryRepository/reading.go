@@ -0,0 +1,84 @@
+package memoryRepository
+
+import (
+	"a21hc3NpZ25tZW50/model"
+	"a21hc3NpZ25tZW50/repository"
+	"fmt"
+	"sort"
+	"time"
+)
+
+func (r *Repository) AddReading(reading model.Reading) (model.Reading, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.create("readings", &reading.Model, time.Now())
+	r.readings = append(r.readings, reading)
+	return reading, nil
+}
+
+func (r *Repository) GetReadingByID(readingID uint) (model.Reading, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, reading := range r.readings {
+		if reading.ID == readingID {
+			return reading, nil
+		}
+	}
+	return model.Reading{}, nil
+}
+
+// readingsWhere returns the matching readings, oldest first.
+func (r *Repository) readingsWhere(match func(model.Reading) bool) []model.Reading {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var readings []model.Reading
+	for _, reading := range r.readings {
+		if match(reading) {
+			readings = append(readings, reading)
+		}
+	}
+	sort.SliceStable(readings, func(i, j int) bool { return readings[i].Date.Before(readings[j].Date) })
+	return readings
+}
+
+func (r *Repository) GetReadingsByUser(userID uint) ([]model.Reading, error) {
+	return r.readingsWhere(func(reading model.Reading) bool { return reading.UserID == userID }), nil
+}
+
+func (r *Repository) GetReadings(userID uint, from, to time.Time) ([]model.Reading, error) {
+	return r.readingsWhere(func(reading model.Reading) bool {
+		return reading.UserID == userID && !reading.Date.Before(from) && reading.Date.Before(to)
+	}), nil
+}
+
+func (r *Repository) UpdateReading(reading model.Reading) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, existing := range r.readings {
+		if existing.ID == reading.ID {
+			reading.UpdatedAt = time.Now()
+			r.readings[i] = reading
+			return nil
+		}
+	}
+	r.create("readings", &reading.Model, time.Now())
+	r.readings = append(r.readings, reading)
+	return nil
+}
+
+func (r *Repository) DeleteReading(readingID uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, reading := range r.readings {
+		if reading.ID == readingID {
+			r.readings = append(r.readings[:i], r.readings[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("reading %w", repository.ErrNotFound)
+}
@@ -0,0 +1,55 @@
+package memoryRepository
+
+import (
+	"a21hc3NpZ25tZW50/model"
+	"sort"
+	"time"
+)
+
+func (r *Repository) AddRecording(recording model.Recording) (model.Recording, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.create("recordings", &recording.Model, time.Now())
+	r.recordings = append(r.recordings, recording)
+	return recording, nil
+}
+
+func (r *Repository) GetRecordingByID(recordingID uint) (model.Recording, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, recording := range r.recordings {
+		if recording.ID == recordingID {
+			return recording, nil
+		}
+	}
+	return model.Recording{}, nil
+}
+
+func (r *Repository) GetRecordingsByMemorize(memorizeID uint) ([]model.Recording, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var recordings []model.Recording
+	for _, recording := range r.recordings {
+		if recording.MemorizeID == memorizeID {
+			recordings = append(recordings, recording)
+		}
+	}
+	sort.SliceStable(recordings, func(i, j int) bool { return recordings[i].CreatedAt.Before(recordings[j].CreatedAt) })
+	return recordings, nil
+}
+
+func (r *Repository) DeleteRecording(recordingID uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, recording := range r.recordings {
+		if recording.ID == recordingID {
+			r.recordings = append(r.recordings[:i], r.recordings[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+// Package memoryRepository keeps everything in memory. It behaves like
+// dbRepository, so the test suite can run without a database, and is safe
+// for concurrent use.
+package memoryRepository
+
+import (
+	"a21hc3NpZ25tZW50/model"
+	"a21hc3NpZ25tZW50/repository"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Repository stores rows in slices ordered by ID. Deleted rows are removed
+// rather than soft deleted, which the API can't tell apart.
+type Repository struct {
+	mu     sync.RWMutex
+	lastID map[string]uint
+
+	users         []model.User
+	memorizes     []model.Memorize
+	groups        []model.Group
+	groupMembers  []model.GroupMember
+	assignments   []model.Assignment
+	recipients    []model.AssignmentRecipient
+	evaluations   []model.Evaluation
+	mistakes      []model.EvaluationMistake
+	activities    []model.Activity
+	goals         []model.Goal
+	rotations     []model.Rotation
+	quizzes       []model.Quiz
+	quizQuestions []model.QuizQuestion
+	recordings    []model.Recording
+	comments      []model.Comment
+	commentReads  []model.CommentRead
+	readings      []model.Reading
+}
+
+var _ repository.Repository = (*Repository)(nil)
+
+func NewRepository() *Repository {
+	return &Repository{lastID: map[string]uint{}}
+}
+
+// create fills in the ID and timestamps of a new row like the database does.
+func (r *Repository) create(table string, row *gorm.Model, now time.Time) {
+	if row.ID == 0 {
+		r.lastID[table]++
+		row.ID = r.lastID[table]
+	} else if row.ID > r.lastID[table] {
+		r.lastID[table] = row.ID
+	}
+	if row.CreatedAt.IsZero() {
+		row.CreatedAt = now
+	}
+	row.UpdatedAt = now
+}
+
+func (r *Repository) AddUser(user model.User) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.users {
+		if existing.Username == user.Username {
+			return "", fmt.Errorf("%w: users.username", repository.ErrConflict)
+		}
+	}
+	if user.Role == "" {
+		user.Role = model.RoleStudent
+	}
+	user.Memorizes = nil
+	r.create("users", &user.Model, time.Now())
+	r.users = append(r.users, user)
+	return user.Username, nil
+}
+
+func (r *Repository) GetUserByUsername(username string) (model.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, user := range r.users {
+		if user.Username == username {
+			return user, nil
+		}
+	}
+	return model.User{}, nil
+}
+
+func (r *Repository) SetUserRole(username, role string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.users {
+		if r.users[i].Username == username {
+			r.users[i].Role = role
+			r.users[i].UpdatedAt = time.Now()
+			return nil
+		}
+	}
+	return fmt.Errorf("user %w", repository.ErrNotFound)
+}
+
+func (r *Repository) GetUserByID(userID uint) (model.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, user := range r.users {
+		if user.ID == userID {
+			return user, nil
+		}
+	}
+	return model.User{}, nil
+}
+
+// loadMemorize returns a copy of a stored record with its derived fields
+// filled in, as GORM's AfterFind hook does.
+func loadMemorize(memorize model.Memorize) model.Memorize {
+	memorize.AfterFind(nil)
+	return memorize
+}
+
+// storeMemorize runs the BeforeSave hook and drops the fields that aren't
+// stored.
+func storeMemorize(memorize model.Memorize) model.Memorize {
+	memorize.BeforeSave(nil)
+	memorize.AccuracyGrade = ""
+	memorize.Page, memorize.Juz, memorize.Hizb, memorize.Rub = "", nil, nil, nil
+	return memorize
+}
+
+func (r *Repository) AddMemorize(memorize model.Memorize) (uint, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	memorize = storeMemorize(memorize)
+	r.create("memorizes", &memorize.Model, time.Now())
+	r.memorizes = append(r.memorizes, memorize)
+	return memorize.ID, nil
+}
+
+func (r *Repository) GetMemorizeByID(memorizeID uint) (model.Memorize, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, memorize := range r.memorizes {
+		if memorize.ID == memorizeID {
+			return loadMemorize(memorize), nil
+		}
+	}
+	return model.Memorize{}, fmt.Errorf("memorize record %w", repository.ErrNotFound)
+}
+
+func (r *Repository) DeleteMemorize(memorizeID uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, memorize := range r.memorizes {
+		if memorize.ID == memorizeID {
+			r.memorizes = append(r.memorizes[:i], r.memorizes[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("memorize record %w", repository.ErrNotFound)
+}
+
+func (r *Repository) memorizesByUser(userID uint) []model.Memorize {
+	var memorizes []model.Memorize
+	for _, memorize := range r.memorizes {
+		if memorize.UserID == userID {
+			memorizes = append(memorizes, loadMemorize(memorize))
+		}
+	}
+	return memorizes
+}
+
+func (r *Repository) GetAllMemorizesByUser(username string) ([]model.Memorize, error) {
+	user, err := r.GetUserByUsername(username)
+	if err != nil || user.ID == 0 {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.memorizesByUser(user.ID), nil
+}
+
+func (r *Repository) GetMemorizesByUserID(userID uint) ([]model.Memorize, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.memorizesByUser(userID), nil
+}
+
+// UpdateMemorize saves the record, creating it when it doesn't exist yet.
+func (r *Repository) UpdateMemorize(memorize model.Memorize) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	memorize = storeMemorize(memorize)
+	for i, existing := range r.memorizes {
+		if existing.ID == memorize.ID {
+			memorize.UpdatedAt = time.Now()
+			r.memorizes[i] = memorize
+			return nil
+		}
+	}
+	r.create("memorizes", &memorize.Model, time.Now())
+	r.memorizes = append(r.memorizes, memorize)
+	sort.Slice(r.memorizes, func(i, j int) bool { return r.memorizes[i].ID < r.memorizes[j].ID })
+	return nil
+}
@@ -0,0 +1,39 @@
+package memoryRepository
+
+import (
+	"a21hc3NpZ25tZW50/model"
+	"a21hc3NpZ25tZW50/repository"
+	"fmt"
+	"time"
+)
+
+func (r *Repository) GetRotationByUser(userID uint) (model.Rotation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, rotation := range r.rotations {
+		if rotation.UserID == userID {
+			return rotation, nil
+		}
+	}
+	return model.Rotation{}, nil
+}
+
+func (r *Repository) SaveRotation(rotation model.Rotation) (model.Rotation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, existing := range r.rotations {
+		switch {
+		case existing.ID == rotation.ID:
+			rotation.UpdatedAt = time.Now()
+			r.rotations[i] = rotation
+			return rotation, nil
+		case existing.UserID == rotation.UserID:
+			return model.Rotation{}, fmt.Errorf("%w: rotations.user_id", repository.ErrConflict)
+		}
+	}
+	r.create("rotations", &rotation.Model, time.Now())
+	r.rotations = append(r.rotations, rotation)
+	return rotation, nil
+}
@@ -0,0 +1,66 @@
+package memoryRepository
+
+import (
+	"a21hc3NpZ25tZW50/quran"
+	"a21hc3NpZ25tZW50/repository"
+	"sort"
+)
+
+func (r *Repository) GetMemorizeSummary(userID uint) (repository.MemorizeSummary, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var summary repository.MemorizeSummary
+	total, scored := 0.0, 0
+	for _, memorize := range r.memorizes {
+		if memorize.UserID != userID {
+			continue
+		}
+		switch {
+		case !memorize.DateCompleted.IsZero():
+			summary.Completed++
+		case !memorize.DateStarted.IsZero():
+			summary.InProgress++
+		default:
+			summary.NotStarted++
+		}
+		if memorize.AccuracyScore != nil {
+			total += *memorize.AccuracyScore
+			scored++
+		}
+	}
+	if scored > 0 {
+		average := total / float64(scored)
+		summary.AverageAccuracy = &average
+	}
+	return summary, nil
+}
+
+func (r *Repository) GetMemorizedSpans(userID uint) ([]quran.Span, error) {
+	r.mu.RLock()
+	var spans []quran.Span
+	for _, memorize := range r.memorizes {
+		if memorize.UserID == userID && memorize.FirstAyah > 0 && !memorize.DateCompleted.IsZero() {
+			spans = append(spans, quran.Span{First: memorize.FirstAyah, Last: memorize.LastAyah})
+		}
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(spans, func(i, j int) bool {
+		if spans[i].First != spans[j].First {
+			return spans[i].First < spans[j].First
+		}
+		return spans[i].Last < spans[j].Last
+	})
+	var merged []quran.Span
+	for _, span := range spans {
+		if n := len(merged); n > 0 && span.First <= merged[n-1].Last+1 {
+			if span.Last > merged[n-1].Last {
+				merged[n-1].Last = span.Last
+			}
+			continue
+		}
+		merged = append(merged, span)
+	}
+	return merged, nil
+}
@@ -0,0 +1,154 @@
+// Package repository declares the storage the service layer depends on.
+// dbRepository implements it with GORM and memoryRepository keeps everything
+// in memory for tests and demos.
+package repository
+
+import (
+	"a21hc3NpZ25tZW50/model"
+	"a21hc3NpZ25tZW50/quran"
+	"time"
+)
+
+// Lookups by ID or name return the zero value, not an error, when nothing is
+// found, unless they say otherwise.
+
+type UserRepository interface {
+	AddUser(user model.User) (string, error)
+	GetUserByUsername(username string) (model.User, error)
+	GetUserByID(userID uint) (model.User, error)
+	// SetUserRole returns an error wrapping ErrNotFound when there is no
+	// such user.
+	SetUserRole(username, role string) error
+}
+
+type MemorizeRepository interface {
+	AddMemorize(memorize model.Memorize) (uint, error)
+	// GetMemorizeByID returns an error wrapping ErrNotFound when there is
+	// no such record.
+	GetMemorizeByID(memorizeID uint) (model.Memorize, error)
+	DeleteMemorize(memorizeID uint) error
+	GetAllMemorizesByUser(username string) ([]model.Memorize, error)
+	GetMemorizesByUserID(userID uint) ([]model.Memorize, error)
+	UpdateMemorize(memorize model.Memorize) error
+	GetMemorizeSummary(userID uint) (MemorizeSummary, error)
+	GetMemorizedSpans(userID uint) ([]quran.Span, error)
+}
+
+type GroupRepository interface {
+	AddGroup(group model.Group) (uint, error)
+	GetGroupByID(groupID uint) (model.Group, error)
+	GetGroupsByTeacher(teacherID uint) ([]model.Group, error)
+	AddGroupMember(member model.GroupMember) error
+	DeleteGroupMember(groupID, userID uint) error
+	IsStudentOfTeacher(teacherID, studentID uint) (bool, error)
+}
+
+type AssignmentRepository interface {
+	AddAssignment(assignment model.Assignment) (uint, error)
+	GetAssignmentByID(assignmentID uint) (model.Assignment, error)
+	GetAssignmentsByTeacher(teacherID uint) ([]model.Assignment, error)
+	GetAssignmentsByRecipient(userID uint) ([]model.Assignment, error)
+	UpdateAssignmentRecipient(recipient model.AssignmentRecipient) error
+	UnlinkMemorizeFromAssignments(memorizeID uint) error
+}
+
+type EvaluationRepository interface {
+	AddEvaluation(evaluation model.Evaluation) (uint, error)
+	GetEvaluationByID(evaluationID uint) (model.Evaluation, error)
+	GetEvaluationsByStudent(studentID uint) ([]model.Evaluation, error)
+	GetEvaluationsByTeacher(teacherID uint) ([]model.Evaluation, error)
+	GetMistakeCountsByStudent(studentID uint) ([]MistakeCount, error)
+}
+
+type ActivityRepository interface {
+	AddActivity(activity model.Activity) error
+	GetActivityTimes(userID uint) ([]time.Time, error)
+	GetActivities(userID uint, from, to time.Time) ([]model.Activity, error)
+}
+
+type GoalRepository interface {
+	AddGoal(goal model.Goal) (uint, error)
+	GetGoalByID(goalID uint) (model.Goal, error)
+	GetGoalsByUser(userID uint) ([]model.Goal, error)
+	UpdateGoal(goal model.Goal) error
+	DeleteGoal(goalID uint) error
+}
+
+type RotationRepository interface {
+	GetRotationByUser(userID uint) (model.Rotation, error)
+	SaveRotation(rotation model.Rotation) (model.Rotation, error)
+}
+
+type QuizRepository interface {
+	AddQuiz(quiz model.Quiz) (uint, error)
+	GetQuizByID(quizID uint) (model.Quiz, error)
+	GetQuizzesByUser(userID uint) ([]model.Quiz, error)
+	UpdateQuiz(quiz model.Quiz) error
+}
+
+type RecordingRepository interface {
+	AddRecording(recording model.Recording) (model.Recording, error)
+	GetRecordingByID(recordingID uint) (model.Recording, error)
+	GetRecordingsByMemorize(memorizeID uint) ([]model.Recording, error)
+	DeleteRecording(recordingID uint) error
+}
+
+type CommentRepository interface {
+	AddComment(comment model.Comment) (model.Comment, error)
+	GetCommentByID(commentID uint) (model.Comment, error)
+	GetCommentsByMemorize(memorizeID uint) ([]model.Comment, error)
+	UpdateComment(comment model.Comment) error
+	DeleteComment(comment model.Comment) error
+	DeleteCommentsByRecording(recordingID uint) error
+	GetCommentReads(userID, memorizeID uint) ([]model.CommentRead, error)
+	SetLastReadAt(userID, memorizeID, recordingID uint, readAt time.Time) error
+	GetUnreadCounts(userID uint) ([]UnreadCount, error)
+}
+
+type ReadingRepository interface {
+	AddReading(reading model.Reading) (model.Reading, error)
+	GetReadingByID(readingID uint) (model.Reading, error)
+	GetReadingsByUser(userID uint) ([]model.Reading, error)
+	GetReadings(userID uint, from, to time.Time) ([]model.Reading, error)
+	UpdateReading(reading model.Reading) error
+	DeleteReading(readingID uint) error
+}
+
+// Repository is everything the service layer stores.
+type Repository interface {
+	UserRepository
+	MemorizeRepository
+	GroupRepository
+	AssignmentRepository
+	EvaluationRepository
+	ActivityRepository
+	GoalRepository
+	RotationRepository
+	QuizRepository
+	RecordingRepository
+	CommentRepository
+	ReadingRepository
+}
+
+type MemorizeSummary struct {
+	Completed       int
+	InProgress      int
+	NotStarted      int
+	AverageAccuracy *float64
+}
+
+// MistakeCount is the number of mistakes of one type a student made on one
+// ayah, summed over all of their evaluations.
+type MistakeCount struct {
+	SurahName string
+	Ayah      int
+	Type      string
+	Count     int
+}
+
+// UnreadCount is the number of comments on a memorize record a user hasn't
+// read yet.
+type UnreadCount struct {
+	MemorizeID uint
+	Unread     int
+}
@@ -0,0 +1,243 @@
+package review
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kind is the type of a review schedule.
+type Kind string
+
+const (
+	Daily    Kind = "daily"
+	Weekly   Kind = "weekly"
+	Biweekly Kind = "biweekly"
+	Monthly  Kind = "monthly"
+	Every    Kind = "every"
+	Custom   Kind = "custom"
+)
+
+var ErrInvalidFrequency = errors.New("invalid review frequency")
+
+// Frequency is a parsed Memorize.ReviewFrequency. It is stored as its
+// canonical string form:
+//
+//	daily, weekly, biweekly, monthly
+//	every:N          every N days
+//	custom:D M W     a cron-like rule with day-of-month, month and
+//	                 day-of-week fields, e.g. "custom:* * mon,thu"
+type Frequency struct {
+	Kind Kind
+	Days int
+	rule *rule
+	text string
+}
+
+var everyPattern = regexp.MustCompile(`^every[\s:_-]*(\d+)([\s_-]*days?)?$`)
+
+// ParseFrequency parses a review frequency. It is case-insensitive and also
+// accepts the spellings used before the value was validated ("Weekly",
+// "every 3 days").
+func ParseFrequency(s string) (Frequency, error) {
+	value := strings.ToLower(strings.TrimSpace(s))
+
+	switch value {
+	case "daily", "every day":
+		return Frequency{Kind: Daily}, nil
+	case "weekly", "every week":
+		return Frequency{Kind: Weekly}, nil
+	case "biweekly", "bi-weekly", "fortnightly", "every two weeks":
+		return Frequency{Kind: Biweekly}, nil
+	case "monthly", "every month":
+		return Frequency{Kind: Monthly}, nil
+	}
+
+	if m := everyPattern.FindStringSubmatch(value); m != nil {
+		days, err := strconv.Atoi(m[1])
+		if err != nil || days < 1 || days > 365 {
+			return Frequency{}, fmt.Errorf("%w: interval must be between 1 and 365 days", ErrInvalidFrequency)
+		}
+		return Frequency{Kind: Every, Days: days}, nil
+	}
+
+	if strings.HasPrefix(value, "custom:") {
+		text := strings.Join(strings.Fields(strings.TrimPrefix(value, "custom:")), " ")
+		r, err := parseRule(text)
+		if err != nil {
+			return Frequency{}, err
+		}
+		return Frequency{Kind: Custom, rule: r, text: text}, nil
+	}
+
+	return Frequency{}, fmt.Errorf("%w: %q (use daily, weekly, biweekly, monthly, every:N or custom:<day> <month> <weekday>)", ErrInvalidFrequency, s)
+}
+
+func (f Frequency) String() string {
+	switch f.Kind {
+	case Every:
+		return fmt.Sprintf("every:%d", f.Days)
+	case Custom:
+		return "custom:" + f.text
+	default:
+		return string(f.Kind)
+	}
+}
+
+// Next returns the review date that follows a review on last, keeping its
+// time of day.
+func (f Frequency) Next(last time.Time) time.Time {
+	switch f.Kind {
+	case Daily:
+		return last.AddDate(0, 0, 1)
+	case Weekly:
+		return last.AddDate(0, 0, 7)
+	case Biweekly:
+		return last.AddDate(0, 0, 14)
+	case Monthly:
+		return last.AddDate(0, 1, 0)
+	case Every:
+		return last.AddDate(0, 0, f.Days)
+	case Custom:
+		// Every valid rule matches at least once within four years.
+		for day := last.AddDate(0, 0, 1); day.Before(last.AddDate(4, 0, 1)); day = day.AddDate(0, 0, 1) {
+			if f.rule.matches(day) {
+				return day
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// rule is the day-of-month, month and day-of-week part of a cron expression.
+type rule struct {
+	days, months, weekdays   []bool
+	anyDay, anyMonth, anyDow bool
+}
+
+var weekdayNames = map[string]int{"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6}
+
+var monthNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+func parseRule(text string) (*rule, error) {
+	fields := strings.Fields(text)
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("%w: custom rule needs 3 fields (day-of-month month day-of-week), got %q", ErrInvalidFrequency, text)
+	}
+
+	r := &rule{}
+	var err error
+	if r.days, r.anyDay, err = parseField(fields[0], 1, 31, nil); err != nil {
+		return nil, err
+	}
+	if r.months, r.anyMonth, err = parseField(fields[1], 1, 12, monthNames); err != nil {
+		return nil, err
+	}
+	if r.weekdays, r.anyDow, err = parseField(fields[2], 0, 7, weekdayNames); err != nil {
+		return nil, err
+	}
+	// Both 0 and 7 mean Sunday, as in cron.
+	if r.weekdays[7] {
+		r.weekdays[0] = true
+	}
+
+	for month := 1; month <= 12; month++ {
+		if !r.months[month] {
+			continue
+		}
+		for day := 1; day <= 31; day++ {
+			if r.days[day] && day <= daysIn(month) {
+				return r, nil
+			}
+		}
+		if !r.anyDow {
+			return r, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: custom rule %q never matches", ErrInvalidFrequency, text)
+}
+
+func daysIn(month int) int {
+	// February counts as 29 so that "29 2 *" is accepted.
+	return time.Date(2024, time.Month(month)+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// parseField parses a cron field made of "*", numbers or names, ranges
+// ("1-5") and steps ("*/2", "1-15/7"), separated by commas.
+func parseField(field string, min, max int, names map[string]int) ([]bool, bool, error) {
+	set := make([]bool, max+1)
+	invalid := fmt.Errorf("%w: bad field %q in custom rule", ErrInvalidFrequency, field)
+
+	value := func(s string) (int, error) {
+		if n, ok := names[s]; ok {
+			return n, nil
+		}
+		n, err := strconv.Atoi(s)
+		if err != nil || n < min || n > max {
+			return 0, invalid
+		}
+		return n, nil
+	}
+
+	for _, part := range strings.Split(field, ",") {
+		span, stepText, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepText)
+			if err != nil || n < 1 {
+				return nil, false, invalid
+			}
+			step = n
+		}
+
+		low, high := min, max
+		if span != "*" {
+			from, to, isRange := strings.Cut(span, "-")
+			var err error
+			if low, err = value(from); err != nil {
+				return nil, false, err
+			}
+			high = low
+			if isRange {
+				if high, err = value(to); err != nil {
+					return nil, false, err
+				}
+			} else if hasStep {
+				high = max
+			}
+			if high < low {
+				return nil, false, invalid
+			}
+		}
+
+		for n := low; n <= high; n += step {
+			set[n] = true
+		}
+	}
+	return set, field == "*", nil
+}
+
+func (r *rule) matches(day time.Time) bool {
+	if !r.months[int(day.Month())] {
+		return false
+	}
+	dom := r.days[day.Day()]
+	dow := r.weekdays[int(day.Weekday())]
+	// Like cron, a restricted day-of-month and day-of-week match either.
+	switch {
+	case r.anyDay && r.anyDow:
+		return true
+	case r.anyDay:
+		return dow
+	case r.anyDow:
+		return dom
+	default:
+		return dom || dow
+	}
+}
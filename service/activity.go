@@ -0,0 +1,221 @@
+package service
+
+import (
+	"a21hc3NpZ25tZW50/metrics"
+	"a21hc3NpZ25tZW50/model"
+	"fmt"
+	"time"
+)
+
+var ErrInvalidActivityQuery = validationError("invalid activity query")
+
+// MaxActivityDays limits the range of a single activity calendar request.
+const MaxActivityDays = 366
+
+const dateLayout = "2006-01-02"
+
+type Streak struct {
+	Current        int
+	Longest        int
+	LastActiveDate string
+	Timezone       string
+}
+
+// ActivityDay counts memorization and review sessions in Sessions, and
+// tilawah separately in Readings.
+type ActivityDay struct {
+	Date           string
+	AyahsMemorized int
+	AyahsReviewed  int
+	Sessions       int
+	AyahsRead      int
+	PagesRead      int
+	MinutesRead    int
+	Readings       int
+}
+
+type ActivityCalendar struct {
+	From     string
+	To       string
+	Timezone string
+	Days     []ActivityDay
+}
+
+func memorizeAyahs(memorize model.Memorize) int {
+	if span, ok := memorize.Span(); ok {
+		return span.Len()
+	}
+	return memorize.TotalAyah
+}
+
+// RecordMemorizeActivity logs the memorization and review sessions implied by
+// a change from previous to memorize (previous is the zero value for a new
+// record).
+func (s *Service) RecordMemorizeActivity(previous, memorize model.Memorize) error {
+	var activities []model.Activity
+	if memorize.IsCompleted() && !memorize.DateCompleted.Equal(previous.DateCompleted) {
+		activities = append(activities, model.Activity{Type: model.ActivityMemorized, OccurredAt: memorize.DateCompleted})
+	}
+	if !memorize.LastReviewDate.IsZero() && !memorize.LastReviewDate.Equal(previous.LastReviewDate) {
+		activities = append(activities, model.Activity{Type: model.ActivityReviewed, OccurredAt: memorize.LastReviewDate})
+	}
+
+	for _, activity := range activities {
+		activity.UserID = memorize.UserID
+		activity.MemorizeID = &memorize.ID
+		activity.Ayahs = memorizeAyahs(memorize)
+		if err := s.addActivity(activity); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Service) addActivity(activity model.Activity) error {
+	if err := s.repository.AddActivity(activity); err != nil {
+		return err
+	}
+	metrics.ActivitiesLogged.WithLabelValues(activity.Type).Inc()
+	return nil
+}
+
+// resolveLocation picks the timezone named in a request, falling back to the
+// user's own.
+func resolveLocation(user model.User, timezone string) (*time.Location, error) {
+	if timezone == "" {
+		return user.Location(), nil
+	}
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTimezone, timezone)
+	}
+	return location, nil
+}
+
+func localDate(t time.Time, location *time.Location) time.Time {
+	year, month, day := t.In(location).Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, location)
+}
+
+// GetStreak counts consecutive days, in the user's timezone, with at least
+// one memorization or review. The current streak is still alive when the last
+// active day was yesterday.
+func (s *Service) GetStreak(user model.User, timezone string, now time.Time) (Streak, error) {
+	location, err := resolveLocation(user, timezone)
+	if err != nil {
+		return Streak{}, err
+	}
+	times, err := s.repository.GetActivityTimes(user.ID)
+	if err != nil {
+		return Streak{}, err
+	}
+
+	streak := Streak{Timezone: location.String()}
+	var last time.Time
+	run := 0
+	for _, t := range times {
+		day := localDate(t, location)
+		switch {
+		case last.IsZero() || day.After(last.AddDate(0, 0, 1)):
+			run = 1
+		case day.Equal(last.AddDate(0, 0, 1)):
+			run++
+		case day.Before(last):
+			// Times are sorted in UTC; a different timezone never reorders
+			// days, so this only guards against bad data.
+			continue
+		}
+		last = day
+		if run > streak.Longest {
+			streak.Longest = run
+		}
+	}
+
+	if !last.IsZero() {
+		streak.LastActiveDate = last.Format(dateLayout)
+		today := localDate(now, location)
+		if !last.Before(today.AddDate(0, 0, -1)) && !last.After(today) {
+			streak.Current = run
+		}
+	}
+	return streak, nil
+}
+
+// GetActivityCalendar returns one entry per day between from and to
+// (inclusive, "YYYY-MM-DD" in the user's timezone) with the ayahs memorized,
+// reviewed and read that day. It defaults to the year up to today.
+func (s *Service) GetActivityCalendar(user model.User, from, to, timezone string, now time.Time) (ActivityCalendar, error) {
+	location, err := resolveLocation(user, timezone)
+	if err != nil {
+		return ActivityCalendar{}, err
+	}
+
+	end := localDate(now, location)
+	if to != "" {
+		if end, err = time.ParseInLocation(dateLayout, to, location); err != nil {
+			return ActivityCalendar{}, fmt.Errorf("%w: to must be a YYYY-MM-DD date", ErrInvalidActivityQuery)
+		}
+	}
+	start := end.AddDate(0, 0, -(MaxActivityDays - 2))
+	if from != "" {
+		if start, err = time.ParseInLocation(dateLayout, from, location); err != nil {
+			return ActivityCalendar{}, fmt.Errorf("%w: from must be a YYYY-MM-DD date", ErrInvalidActivityQuery)
+		}
+	}
+	if end.Before(start) {
+		return ActivityCalendar{}, fmt.Errorf("%w: from is after to", ErrInvalidActivityQuery)
+	}
+	if start.AddDate(0, 0, MaxActivityDays).Before(end.AddDate(0, 0, 1)) {
+		return ActivityCalendar{}, fmt.Errorf("%w: at most %d days can be requested", ErrInvalidActivityQuery, MaxActivityDays)
+	}
+
+	activities, err := s.repository.GetActivities(user.ID, start, end.AddDate(0, 0, 1))
+	if err != nil {
+		return ActivityCalendar{}, err
+	}
+
+	calendar := ActivityCalendar{
+		From:     start.Format(dateLayout),
+		To:       end.Format(dateLayout),
+		Timezone: location.String(),
+	}
+	index := map[string]int{}
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		index[day.Format(dateLayout)] = len(calendar.Days)
+		calendar.Days = append(calendar.Days, ActivityDay{Date: day.Format(dateLayout)})
+	}
+
+	for _, activity := range activities {
+		i, ok := index[localDate(activity.OccurredAt, location).Format(dateLayout)]
+		if !ok {
+			continue
+		}
+		day := &calendar.Days[i]
+		day.Sessions++
+		switch activity.Type {
+		case model.ActivityMemorized:
+			day.AyahsMemorized += activity.Ayahs
+		case model.ActivityReviewed:
+			day.AyahsReviewed += activity.Ayahs
+		}
+	}
+
+	// Readings are dated in the user's own timezone, whichever one the
+	// calendar is shown in, so look a day either side.
+	readings, err := s.repository.GetReadings(user.ID, start.AddDate(0, 0, -1), end.AddDate(0, 0, 2))
+	if err != nil {
+		return ActivityCalendar{}, err
+	}
+	for _, reading := range readings {
+		i, ok := index[localDate(reading.Date, user.Location()).Format(dateLayout)]
+		if !ok {
+			continue
+		}
+		day := &calendar.Days[i]
+		day.Readings++
+		day.AyahsRead += reading.Ayahs
+		day.PagesRead += reading.Pages
+		day.MinutesRead += reading.Duration
+	}
+	return calendar, nil
+}
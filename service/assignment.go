@@ -0,0 +1,322 @@
+package service
+
+import (
+	"a21hc3NpZ25tZW50/model"
+	"a21hc3NpZ25tZW50/quran"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrNotTeacher         = forbiddenError("only teachers can manage groups and assignments")
+	ErrGroupNotFound      = notFoundError("group not found")
+	ErrAssignmentNotFound = notFoundError("assignment not found")
+	ErrUserNotFound       = notFoundError("user not found")
+	ErrMemberNotFound     = notFoundError("student is not a member of this group")
+	ErrNoRecipients       = validationError("assignment needs at least one student or group")
+	ErrInvalidAssignment  = validationError("invalid assignment")
+)
+
+type AssignmentInput struct {
+	SurahName string
+	StartAyah int
+	EndAyah   int
+	DueDate   time.Time
+	Notes     string
+	Usernames []string
+	GroupID   *uint
+}
+
+func (s *Service) CreateGroup(teacher model.User, name string) (model.Group, error) {
+	if teacher.Role != model.RoleTeacher {
+		return model.Group{}, ErrNotTeacher
+	}
+	if name == "" {
+		return model.Group{}, fmt.Errorf("%w: group name is required", ErrInvalidAssignment)
+	}
+
+	group := model.Group{Name: name, TeacherID: teacher.ID}
+	id, err := s.repository.AddGroup(group)
+	if err != nil {
+		return model.Group{}, err
+	}
+	group.ID = id
+	return group, nil
+}
+
+func (s *Service) GetGroups(teacher model.User) ([]model.Group, error) {
+	if teacher.Role != model.RoleTeacher {
+		return nil, ErrNotTeacher
+	}
+	return s.repository.GetGroupsByTeacher(teacher.ID)
+}
+
+func (s *Service) getOwnGroup(teacher model.User, groupID uint) (model.Group, error) {
+	if teacher.Role != model.RoleTeacher {
+		return model.Group{}, ErrNotTeacher
+	}
+	group, err := s.repository.GetGroupByID(groupID)
+	if err != nil {
+		return model.Group{}, err
+	}
+	if group.ID == 0 || group.TeacherID != teacher.ID {
+		return model.Group{}, ErrGroupNotFound
+	}
+	return group, nil
+}
+
+func (s *Service) AddGroupMember(teacher model.User, groupID uint, username string) (model.Group, error) {
+	group, err := s.getOwnGroup(teacher, groupID)
+	if err != nil {
+		return model.Group{}, err
+	}
+
+	student, err := s.repository.GetUserByUsername(username)
+	if err != nil {
+		return model.Group{}, err
+	}
+	if student.ID == 0 {
+		return model.Group{}, ErrUserNotFound
+	}
+
+	for _, member := range group.Members {
+		if member.UserID == student.ID {
+			return group, nil
+		}
+	}
+
+	if err := s.repository.AddGroupMember(model.GroupMember{GroupID: group.ID, UserID: student.ID}); err != nil {
+		return model.Group{}, err
+	}
+	return s.repository.GetGroupByID(group.ID)
+}
+
+func (s *Service) RemoveGroupMember(teacher model.User, groupID uint, userID uint) error {
+	group, err := s.getOwnGroup(teacher, groupID)
+	if err != nil {
+		return err
+	}
+	for _, member := range group.Members {
+		if member.UserID == userID {
+			return s.repository.DeleteGroupMember(group.ID, userID)
+		}
+	}
+	return ErrMemberNotFound
+}
+
+// CreateAssignment validates the target passage and creates one recipient row
+// per student, expanding the group (if any) to its current members.
+func (s *Service) CreateAssignment(teacher model.User, input AssignmentInput) (model.Assignment, error) {
+	if teacher.Role != model.RoleTeacher {
+		return model.Assignment{}, ErrNotTeacher
+	}
+
+	surah, ok := quran.LookupSurah(input.SurahName)
+	if !ok {
+		return model.Assignment{}, fmt.Errorf("%w: unknown surah %q", ErrInvalidAssignment, input.SurahName)
+	}
+	target := quran.AyahRange{Start: input.StartAyah, End: input.EndAyah}
+	if err := surah.Validate(target); err != nil {
+		return model.Assignment{}, fmt.Errorf("%w: %v", ErrInvalidAssignment, err)
+	}
+	if input.DueDate.IsZero() {
+		return model.Assignment{}, fmt.Errorf("%w: due date is required", ErrInvalidAssignment)
+	}
+
+	userIDs := []uint{}
+	seen := map[uint]bool{}
+	addRecipient := func(id uint) {
+		if !seen[id] {
+			seen[id] = true
+			userIDs = append(userIDs, id)
+		}
+	}
+
+	if input.GroupID != nil {
+		group, err := s.getOwnGroup(teacher, *input.GroupID)
+		if err != nil {
+			return model.Assignment{}, err
+		}
+		for _, member := range group.Members {
+			addRecipient(member.UserID)
+		}
+	}
+	for _, username := range input.Usernames {
+		student, err := s.repository.GetUserByUsername(username)
+		if err != nil {
+			return model.Assignment{}, err
+		}
+		if student.ID == 0 {
+			return model.Assignment{}, fmt.Errorf("%w: %s", ErrUserNotFound, username)
+		}
+		addRecipient(student.ID)
+	}
+	if len(userIDs) == 0 {
+		return model.Assignment{}, ErrNoRecipients
+	}
+
+	assignment := model.Assignment{
+		TeacherID: teacher.ID,
+		GroupID:   input.GroupID,
+		SurahName: surah.Name,
+		StartAyah: target.Start,
+		EndAyah:   target.End,
+		DueDate:   input.DueDate,
+		Notes:     input.Notes,
+	}
+	for _, id := range userIDs {
+		assignment.Recipients = append(assignment.Recipients, model.AssignmentRecipient{
+			UserID: id,
+			Status: model.AssignmentStatusAssigned,
+		})
+	}
+
+	id, err := s.repository.AddAssignment(assignment)
+	if err != nil {
+		return model.Assignment{}, err
+	}
+	created, err := s.repository.GetAssignmentByID(id)
+	if err != nil {
+		return model.Assignment{}, err
+	}
+
+	// Students may already have memorized the target before it was assigned.
+	for _, recipient := range created.Recipients {
+		memorizes, err := s.repository.GetMemorizesByUserID(recipient.UserID)
+		if err != nil {
+			return model.Assignment{}, err
+		}
+		for _, memorize := range memorizes {
+			if err := s.SyncAssignments(memorize); err != nil {
+				return model.Assignment{}, err
+			}
+		}
+	}
+	return s.GetAssignment(teacher, id)
+}
+
+// GetAssignments returns the assignments a teacher created, or the ones
+// given to a student.
+func (s *Service) GetAssignments(user model.User) ([]model.Assignment, error) {
+	var (
+		assignments []model.Assignment
+		err         error
+	)
+	if user.Role == model.RoleTeacher {
+		assignments, err = s.repository.GetAssignmentsByTeacher(user.ID)
+	} else {
+		assignments, err = s.repository.GetAssignmentsByRecipient(user.ID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for i := range assignments {
+		markOverdue(&assignments[i], now)
+	}
+	return assignments, nil
+}
+
+func (s *Service) GetAssignment(user model.User, assignmentID uint) (model.Assignment, error) {
+	assignment, err := s.repository.GetAssignmentByID(assignmentID)
+	if err != nil {
+		return model.Assignment{}, err
+	}
+	if assignment.ID == 0 {
+		return model.Assignment{}, ErrAssignmentNotFound
+	}
+
+	if assignment.TeacherID != user.ID {
+		// Students only see their own progress.
+		var own []model.AssignmentRecipient
+		for _, recipient := range assignment.Recipients {
+			if recipient.UserID == user.ID {
+				own = append(own, recipient)
+			}
+		}
+		if len(own) == 0 {
+			return model.Assignment{}, ErrAssignmentNotFound
+		}
+		assignment.Recipients = own
+	}
+
+	markOverdue(&assignment, time.Now())
+	return assignment, nil
+}
+
+// markOverdue reports unfinished recipients past the due date as overdue.
+// The status is only changed for the response and never persisted.
+func markOverdue(assignment *model.Assignment, now time.Time) {
+	if !now.After(assignment.DueDate) {
+		return
+	}
+	for i := range assignment.Recipients {
+		if assignment.Recipients[i].Status != model.AssignmentStatusCompleted {
+			assignment.Recipients[i].Status = model.AssignmentStatusOverdue
+		}
+	}
+}
+
+// SyncAssignments links a student's Memorize record to the assignments it
+// fulfils. A record that covers the whole target completes the assignment
+// once it is marked complete; a record that only overlaps the target marks it
+// in progress.
+func (s *Service) SyncAssignments(memorize model.Memorize) error {
+	surah, knownSurah := quran.LookupSurah(memorize.SurahName)
+	memorized, err := quran.ParseAyahRange(memorize.AyahRange)
+	if err != nil {
+		knownSurah = false
+	}
+
+	assignments, err := s.repository.GetAssignmentsByRecipient(memorize.UserID)
+	if err != nil {
+		return err
+	}
+
+	for _, assignment := range assignments {
+		target := quran.AyahRange{Start: assignment.StartAyah, End: assignment.EndAyah}
+		sameSurah := knownSurah && assignment.SurahName == surah.Name
+		covers := sameSurah && memorized.Contains(target)
+		overlaps := sameSurah && memorized.Overlaps(target)
+
+		for _, recipient := range assignment.Recipients {
+			linkedHere := recipient.MemorizeID != nil && *recipient.MemorizeID == memorize.ID
+			if recipient.MemorizeID != nil && !linkedHere {
+				// Already tracked through another record; only take over
+				// when this one fully covers the target.
+				if recipient.Status == model.AssignmentStatusCompleted || !covers {
+					continue
+				}
+			}
+
+			updated := recipient
+			switch {
+			case covers && memorize.IsCompleted():
+				updated.MemorizeID = &memorize.ID
+				updated.Status = model.AssignmentStatusCompleted
+				updated.CompletedAt = memorize.DateCompleted
+			case overlaps:
+				updated.MemorizeID = &memorize.ID
+				updated.Status = model.AssignmentStatusInProgress
+				updated.CompletedAt = time.Time{}
+			case linkedHere:
+				updated.MemorizeID = nil
+				updated.Status = model.AssignmentStatusAssigned
+				updated.CompletedAt = time.Time{}
+			default:
+				continue
+			}
+
+			if err := s.repository.UpdateAssignmentRecipient(updated); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// UnlinkMemorize is called when a Memorize record is deleted.
+func (s *Service) UnlinkMemorize(memorizeID uint) error {
+	return s.repository.UnlinkMemorizeFromAssignments(memorizeID)
+}
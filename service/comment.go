@@ -0,0 +1,237 @@
+package service
+
+import (
+	"a21hc3NpZ25tZW50/model"
+	"a21hc3NpZ25tZW50/quran"
+	"a21hc3NpZ25tZW50/repository"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrCommentNotFound = notFoundError("comment not found")
+	ErrInvalidComment  = validationError("invalid comment")
+)
+
+const MaxCommentLength = 2000
+
+type CommentInput struct {
+	Body        string
+	RecordingID *uint
+	ParentID    *uint
+	Timestamp   *float64
+	Ayah        *int
+}
+
+type CommentThread struct {
+	MemorizeID uint
+	Unread     int
+	Comments   []model.Comment
+}
+
+type UnreadComments struct {
+	Total     int
+	Memorizes []repository.UnreadCount
+}
+
+func validateCommentBody(body string) (string, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return "", fmt.Errorf("%w: body is required", ErrInvalidComment)
+	}
+	if len([]rune(body)) > MaxCommentLength {
+		return "", fmt.Errorf("%w: body can be at most %d characters", ErrInvalidComment, MaxCommentLength)
+	}
+	return body, nil
+}
+
+// AddComment posts a comment on a memorize record, which the owner of the
+// record and their teachers can do. A comment can point at a moment of one
+// of the record's recordings and at one of its ayahs, and replies stay on
+// the recording of the comment they answer.
+func (s *Service) AddComment(user model.User, memorizeID uint, input CommentInput) (model.Comment, error) {
+	memorize, err := s.getViewableMemorize(user, memorizeID)
+	if err != nil {
+		return model.Comment{}, err
+	}
+
+	comment := model.Comment{
+		MemorizeID:  memorize.ID,
+		RecordingID: input.RecordingID,
+		ParentID:    input.ParentID,
+		AuthorID:    user.ID,
+		Timestamp:   input.Timestamp,
+		Ayah:        input.Ayah,
+	}
+	comment.Body, err = validateCommentBody(input.Body)
+	if err != nil {
+		return model.Comment{}, err
+	}
+
+	if comment.ParentID != nil {
+		parent, err := s.repository.GetCommentByID(*comment.ParentID)
+		if err != nil {
+			return model.Comment{}, err
+		}
+		if parent.ID == 0 || parent.MemorizeID != memorize.ID {
+			return model.Comment{}, fmt.Errorf("%w: the comment replied to isn't on this memorize record", ErrInvalidComment)
+		}
+		if comment.RecordingID == nil {
+			comment.RecordingID = parent.RecordingID
+		} else if parent.RecordingID == nil || *parent.RecordingID != *comment.RecordingID {
+			return model.Comment{}, fmt.Errorf("%w: a reply must be on the same recording as its comment", ErrInvalidComment)
+		}
+	}
+
+	var recording model.Recording
+	if comment.RecordingID != nil {
+		recording, err = s.repository.GetRecordingByID(*comment.RecordingID)
+		if err != nil {
+			return model.Comment{}, err
+		}
+		if recording.ID == 0 || recording.MemorizeID != memorize.ID {
+			return model.Comment{}, fmt.Errorf("%w: the recording isn't of this memorize record", ErrInvalidComment)
+		}
+	}
+	if comment.Timestamp != nil {
+		switch {
+		case comment.RecordingID == nil:
+			return model.Comment{}, fmt.Errorf("%w: a timestamp needs a recording", ErrInvalidComment)
+		case *comment.Timestamp < 0, recording.Duration != nil && *comment.Timestamp > *recording.Duration:
+			return model.Comment{}, fmt.Errorf("%w: the timestamp is outside the recording", ErrInvalidComment)
+		}
+	}
+
+	if comment.Ayah != nil {
+		ayahs, err := quran.ParseAyahRange(memorize.AyahRange)
+		if err != nil || *comment.Ayah < ayahs.Start || *comment.Ayah > ayahs.End {
+			return model.Comment{}, fmt.Errorf("%w: ayah %d isn't part of %s", ErrInvalidComment, *comment.Ayah, memorize.AyahRange)
+		}
+	}
+
+	return s.repository.AddComment(comment)
+}
+
+// GetComments returns the comments on a memorize record as threads, oldest
+// first, optionally only those on one recording. Comments by others posted
+// since the user last read them, on the record or on their recording, are
+// marked unread, and what was listed is marked read as of now: all of the
+// record's comments, or only the recording's.
+func (s *Service) GetComments(user model.User, memorizeID uint, recordingID *uint, now time.Time) (CommentThread, error) {
+	memorize, err := s.getViewableMemorize(user, memorizeID)
+	if err != nil {
+		return CommentThread{}, err
+	}
+	comments, err := s.repository.GetCommentsByMemorize(memorize.ID)
+	if err != nil {
+		return CommentThread{}, err
+	}
+	reads, err := s.repository.GetCommentReads(user.ID, memorize.ID)
+	if err != nil {
+		return CommentThread{}, err
+	}
+	// When the record's comments, at 0, and each recording's were last read.
+	lastRead := map[uint]time.Time{}
+	for _, read := range reads {
+		lastRead[read.RecordingID] = read.LastReadAt
+	}
+
+	thread := CommentThread{MemorizeID: memorize.ID, Comments: []model.Comment{}}
+	included := map[uint]bool{}
+	var selected []model.Comment
+	for _, comment := range comments {
+		if recordingID != nil && (comment.RecordingID == nil || *comment.RecordingID != *recordingID) {
+			continue
+		}
+		readAt := lastRead[0]
+		if comment.RecordingID != nil && lastRead[*comment.RecordingID].After(readAt) {
+			readAt = lastRead[*comment.RecordingID]
+		}
+		comment.Unread = comment.AuthorID != user.ID && comment.CreatedAt.After(readAt)
+		if comment.Unread {
+			thread.Unread++
+		}
+		included[comment.ID] = true
+		selected = append(selected, comment)
+	}
+
+	replies := map[uint][]model.Comment{}
+	for _, comment := range selected {
+		if comment.ParentID != nil && included[*comment.ParentID] {
+			replies[*comment.ParentID] = append(replies[*comment.ParentID], comment)
+		} else {
+			thread.Comments = append(thread.Comments, comment)
+		}
+	}
+	var attach func(comments []model.Comment)
+	attach = func(comments []model.Comment) {
+		for i := range comments {
+			comments[i].Replies = replies[comments[i].ID]
+			attach(comments[i].Replies)
+		}
+	}
+	attach(thread.Comments)
+
+	var listed uint
+	if recordingID != nil {
+		listed = *recordingID
+	}
+	if err := s.repository.SetLastReadAt(user.ID, memorize.ID, listed, now); err != nil {
+		return CommentThread{}, err
+	}
+	return thread, nil
+}
+
+func (s *Service) getOwnComment(user model.User, commentID uint) (model.Comment, error) {
+	comment, err := s.repository.GetCommentByID(commentID)
+	if err != nil {
+		return model.Comment{}, err
+	}
+	if comment.ID == 0 || comment.AuthorID != user.ID {
+		return model.Comment{}, ErrCommentNotFound
+	}
+	return comment, nil
+}
+
+// UpdateComment lets authors edit the body of their comments.
+func (s *Service) UpdateComment(user model.User, commentID uint, body string, now time.Time) (model.Comment, error) {
+	comment, err := s.getOwnComment(user, commentID)
+	if err != nil {
+		return model.Comment{}, err
+	}
+	comment.Body, err = validateCommentBody(body)
+	if err != nil {
+		return model.Comment{}, err
+	}
+	comment.EditedAt = &now
+	if err := s.repository.UpdateComment(comment); err != nil {
+		return model.Comment{}, err
+	}
+	return comment, nil
+}
+
+// DeleteComment lets authors delete their comments. Replies to it are kept
+// and move up to the comment it answered.
+func (s *Service) DeleteComment(user model.User, commentID uint) error {
+	comment, err := s.getOwnComment(user, commentID)
+	if err != nil {
+		return err
+	}
+	return s.repository.DeleteComment(comment)
+}
+
+// GetUnreadComments counts the unread comments on the user's memorize
+// records and, for teachers, on those of their students.
+func (s *Service) GetUnreadComments(user model.User) (UnreadComments, error) {
+	counts, err := s.repository.GetUnreadCounts(user.ID)
+	if err != nil {
+		return UnreadComments{}, err
+	}
+	unread := UnreadComments{Memorizes: []repository.UnreadCount{}}
+	for _, count := range counts {
+		unread.Total += count.Unread
+		unread.Memorizes = append(unread.Memorizes, count)
+	}
+	return unread, nil
+}
@@ -0,0 +1,33 @@
+package service
+
+import (
+	"a21hc3NpZ25tZW50/repository"
+	"errors"
+)
+
+// The kinds of error the service returns. Every error caused by the caller
+// wraps one of them, so transports can answer an error without knowing it.
+var (
+	ErrNotFound   = repository.ErrNotFound
+	ErrConflict   = repository.ErrConflict
+	ErrValidation = errors.New("validation failed")
+	ErrForbidden  = errors.New("forbidden")
+)
+
+// kindError is an error of one of the kinds above, with its own message.
+type kindError struct {
+	kind    error
+	message string
+}
+
+func (e *kindError) Error() string { return e.message }
+
+func (e *kindError) Unwrap() error { return e.kind }
+
+func notFoundError(message string) error { return &kindError{ErrNotFound, message} }
+
+func conflictError(message string) error { return &kindError{ErrConflict, message} }
+
+func validationError(message string) error { return &kindError{ErrValidation, message} }
+
+func forbiddenError(message string) error { return &kindError{ErrForbidden, message} }
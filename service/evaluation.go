@@ -0,0 +1,308 @@
+package service
+
+import (
+	"a21hc3NpZ25tZW50/model"
+	"a21hc3NpZ25tZW50/quran"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+var (
+	ErrEvaluationNotFound = notFoundError("evaluation not found")
+	ErrInvalidEvaluation  = validationError("invalid evaluation")
+	ErrNotYourStudent     = forbiddenError("student is not in any of your groups")
+)
+
+// MistakeWeights is how much of an ayah's share of the score a single mistake
+// of each type costs. An ayah can never score below zero.
+var MistakeWeights = map[string]float64{
+	model.MistakeForgottenWord: 0.5,
+	model.MistakeHarakat:       0.25,
+	model.MistakeMakhraj:       0.2,
+	model.MistakeTajweed:       0.1,
+}
+
+type EvaluationInput struct {
+	Username    string
+	MemorizeID  *uint
+	SurahName   string
+	StartAyah   int
+	EndAyah     int
+	EvaluatedAt time.Time
+	Notes       string
+	Mistakes    []MistakeInput
+}
+
+type MistakeInput struct {
+	Ayah int
+	Type string
+	Word string
+	Note string
+}
+
+// WeakAyah summarises the mistakes a student keeps making on one ayah.
+type WeakAyah struct {
+	SurahName      string
+	Ayah           int
+	Mistakes       int
+	MistakesByType map[string]int
+	TimesEvaluated int
+	MistakeRate    float64
+	LastMistakeAt  time.Time
+}
+
+// ScoreEvaluation gives every ayah in the range an equal share of 100 points
+// and deducts the weight of each mistake from the ayah it was made in.
+func ScoreEvaluation(target quran.AyahRange, mistakes []model.EvaluationMistake) float64 {
+	penalties := map[int]float64{}
+	for _, mistake := range mistakes {
+		penalties[mistake.Ayah] += MistakeWeights[mistake.Type]
+	}
+
+	total := 0.0
+	for ayah := target.Start; ayah <= target.End; ayah++ {
+		total += math.Max(0, 1-penalties[ayah])
+	}
+	return math.Round(total/float64(target.Len())*10000) / 100
+}
+
+func (s *Service) CreateEvaluation(teacher model.User, input EvaluationInput) (model.Evaluation, error) {
+	if teacher.Role != model.RoleTeacher {
+		return model.Evaluation{}, ErrNotTeacher
+	}
+
+	student, err := s.repository.GetUserByUsername(input.Username)
+	if err != nil {
+		return model.Evaluation{}, err
+	}
+	if student.ID == 0 {
+		return model.Evaluation{}, fmt.Errorf("%w: %s", ErrUserNotFound, input.Username)
+	}
+	if ok, err := s.repository.IsStudentOfTeacher(teacher.ID, student.ID); err != nil {
+		return model.Evaluation{}, err
+	} else if !ok {
+		return model.Evaluation{}, ErrNotYourStudent
+	}
+
+	var memorize model.Memorize
+	if input.MemorizeID != nil {
+		memorize, err = s.findMemorize(*input.MemorizeID)
+		if err != nil && !errors.Is(err, ErrMemorizeNotFound) {
+			return model.Evaluation{}, err
+		}
+		if err != nil || memorize.UserID != student.ID {
+			return model.Evaluation{}, fmt.Errorf("%w: memorize record not found for %s", ErrInvalidEvaluation, student.Username)
+		}
+		// The tested passage defaults to the whole memorize record.
+		if input.SurahName == "" {
+			input.SurahName = memorize.SurahName
+			if r, err := quran.ParseAyahRange(memorize.AyahRange); err == nil {
+				input.StartAyah, input.EndAyah = r.Start, r.End
+			}
+		}
+	}
+
+	surah, ok := quran.LookupSurah(input.SurahName)
+	if !ok {
+		return model.Evaluation{}, fmt.Errorf("%w: unknown surah %q", ErrInvalidEvaluation, input.SurahName)
+	}
+	target := quran.AyahRange{Start: input.StartAyah, End: input.EndAyah}
+	if err := surah.Validate(target); err != nil {
+		return model.Evaluation{}, fmt.Errorf("%w: %v", ErrInvalidEvaluation, err)
+	}
+	// The score replaces the record's accuracy, so the passage has to be
+	// part of the record.
+	if memorize.ID != 0 {
+		recorded, _ := quran.LookupSurah(memorize.SurahName)
+		ayahs, err := quran.ParseAyahRange(memorize.AyahRange)
+		if err != nil || recorded.Number != surah.Number || !ayahs.Contains(target) {
+			return model.Evaluation{}, fmt.Errorf("%w: the passage must be within the memorize record, %s %s", ErrInvalidEvaluation, memorize.SurahName, memorize.AyahRange)
+		}
+	}
+
+	evaluation := model.Evaluation{
+		TeacherID:   teacher.ID,
+		StudentID:   student.ID,
+		MemorizeID:  input.MemorizeID,
+		SurahName:   surah.Name,
+		StartAyah:   target.Start,
+		EndAyah:     target.End,
+		EvaluatedAt: input.EvaluatedAt,
+		Notes:       input.Notes,
+	}
+	if evaluation.EvaluatedAt.IsZero() {
+		evaluation.EvaluatedAt = time.Now()
+	}
+
+	for _, mistake := range input.Mistakes {
+		if _, ok := MistakeWeights[mistake.Type]; !ok {
+			return model.Evaluation{}, fmt.Errorf("%w: unknown mistake type %q", ErrInvalidEvaluation, mistake.Type)
+		}
+		if mistake.Ayah < target.Start || mistake.Ayah > target.End {
+			return model.Evaluation{}, fmt.Errorf("%w: ayah %d is outside %s", ErrInvalidEvaluation, mistake.Ayah, target)
+		}
+		evaluation.Mistakes = append(evaluation.Mistakes, model.EvaluationMistake{
+			Ayah: mistake.Ayah,
+			Type: mistake.Type,
+			Word: mistake.Word,
+			Note: mistake.Note,
+		})
+	}
+	evaluation.Score = ScoreEvaluation(target, evaluation.Mistakes)
+
+	id, err := s.repository.AddEvaluation(evaluation)
+	if err != nil {
+		return model.Evaluation{}, err
+	}
+
+	// A tasmi' session counts as a review for the student's streak.
+	err = s.addActivity(model.Activity{
+		UserID:     student.ID,
+		MemorizeID: input.MemorizeID,
+		Type:       model.ActivityReviewed,
+		Ayahs:      target.Len(),
+		OccurredAt: evaluation.EvaluatedAt,
+	})
+	if err != nil {
+		return model.Evaluation{}, err
+	}
+
+	if memorize.ID != 0 {
+		memorize.AccuracyScore = &evaluation.Score
+		if err := s.repository.UpdateMemorize(memorize); err != nil {
+			return model.Evaluation{}, err
+		}
+	}
+
+	return s.repository.GetEvaluationByID(id)
+}
+
+// canViewStudent allows students to see their own evaluations and teachers
+// to see those of the students in their groups.
+func (s *Service) canViewStudent(user model.User, studentID uint) error {
+	if user.ID == studentID {
+		return nil
+	}
+	if user.Role != model.RoleTeacher {
+		return ErrNotTeacher
+	}
+	ok, err := s.repository.IsStudentOfTeacher(user.ID, studentID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrNotYourStudent
+	}
+	return nil
+}
+
+func (s *Service) GetEvaluation(user model.User, evaluationID uint) (model.Evaluation, error) {
+	evaluation, err := s.repository.GetEvaluationByID(evaluationID)
+	if err != nil {
+		return model.Evaluation{}, err
+	}
+	if evaluation.ID == 0 || (evaluation.StudentID != user.ID && evaluation.TeacherID != user.ID) {
+		return model.Evaluation{}, ErrEvaluationNotFound
+	}
+	return evaluation, nil
+}
+
+// GetEvaluations lists the evaluations a teacher recorded, or the ones a
+// student received.
+func (s *Service) GetEvaluations(user model.User) ([]model.Evaluation, error) {
+	if user.Role == model.RoleTeacher {
+		return s.repository.GetEvaluationsByTeacher(user.ID)
+	}
+	return s.repository.GetEvaluationsByStudent(user.ID)
+}
+
+func (s *Service) findStudent(user model.User, username string) (model.User, error) {
+	student, err := s.repository.GetUserByUsername(username)
+	if err != nil {
+		return model.User{}, err
+	}
+	if student.ID == 0 {
+		return model.User{}, fmt.Errorf("%w: %s", ErrUserNotFound, username)
+	}
+	if err := s.canViewStudent(user, student.ID); err != nil {
+		return model.User{}, err
+	}
+	return student, nil
+}
+
+func (s *Service) GetStudentEvaluations(user model.User, username string) ([]model.Evaluation, error) {
+	student, err := s.findStudent(user, username)
+	if err != nil {
+		return nil, err
+	}
+	return s.repository.GetEvaluationsByStudent(student.ID)
+}
+
+// GetWeakAyahs ranks the ayahs a student has made mistakes on across all of
+// their evaluations, most mistakes first.
+func (s *Service) GetWeakAyahs(user model.User, username string) ([]WeakAyah, error) {
+	student, err := s.findStudent(user, username)
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := s.repository.GetMistakeCountsByStudent(student.ID)
+	if err != nil {
+		return nil, err
+	}
+	evaluations, err := s.repository.GetEvaluationsByStudent(student.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	type ayahKey struct {
+		surah string
+		ayah  int
+	}
+	weak := map[ayahKey]*WeakAyah{}
+	for _, count := range counts {
+		key := ayahKey{count.SurahName, count.Ayah}
+		if weak[key] == nil {
+			weak[key] = &WeakAyah{SurahName: count.SurahName, Ayah: count.Ayah, MistakesByType: map[string]int{}}
+		}
+		weak[key].Mistakes += count.Count
+		weak[key].MistakesByType[count.Type] += count.Count
+	}
+
+	for _, evaluation := range evaluations {
+		for ayah := evaluation.StartAyah; ayah <= evaluation.EndAyah; ayah++ {
+			if w := weak[ayahKey{evaluation.SurahName, ayah}]; w != nil {
+				w.TimesEvaluated++
+			}
+		}
+		for _, mistake := range evaluation.Mistakes {
+			w := weak[ayahKey{evaluation.SurahName, mistake.Ayah}]
+			if w != nil && evaluation.EvaluatedAt.After(w.LastMistakeAt) {
+				w.LastMistakeAt = evaluation.EvaluatedAt
+			}
+		}
+	}
+
+	result := make([]WeakAyah, 0, len(weak))
+	for _, w := range weak {
+		if w.TimesEvaluated > 0 {
+			w.MistakeRate = math.Round(float64(w.Mistakes)/float64(w.TimesEvaluated)*100) / 100
+		}
+		result = append(result, *w)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Mistakes != result[j].Mistakes {
+			return result[i].Mistakes > result[j].Mistakes
+		}
+		if result[i].SurahName != result[j].SurahName {
+			a, _ := quran.LookupSurah(result[i].SurahName)
+			b, _ := quran.LookupSurah(result[j].SurahName)
+			return a.Number < b.Number
+		}
+		return result[i].Ayah < result[j].Ayah
+	})
+	return result, nil
+}
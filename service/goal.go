@@ -0,0 +1,441 @@
+package service
+
+import (
+	"a21hc3NpZ25tZW50/model"
+	"a21hc3NpZ25tZW50/quran"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrGoalNotFound = notFoundError("goal not found")
+	ErrInvalidGoal  = validationError("invalid goal")
+)
+
+const (
+	GoalStatusAhead    = "ahead"
+	GoalStatusOnTrack  = "on_track"
+	GoalStatusBehind   = "behind"
+	GoalStatusAchieved = "achieved"
+	GoalStatusMissed   = "missed"
+)
+
+type GoalInput struct {
+	Title      string
+	Scope      string
+	Surah      string
+	Juz        int
+	Direction  string
+	StartDate  string
+	TargetDate string
+	DailyPace  int
+}
+
+type GoalProgress struct {
+	Goal           model.Goal
+	Status         string
+	TotalAyahs     int
+	MemorizedAyahs int
+	// PlannedAyahs is how many ayahs of the scope should be memorized by
+	// the end of today according to the plan.
+	PlannedAyahs int
+	Percentage   float64
+	DaysLeft     int
+}
+
+type PlanDay struct {
+	Date         string
+	Segments     []PlanSegment
+	Ayahs        int
+	PlannedTotal int
+}
+
+// PlanSegment is a run of ayahs within one surah.
+type PlanSegment struct {
+	Surah     int
+	SurahName string
+	AyahRange string
+}
+
+type GoalPlan struct {
+	Progress GoalProgress
+	Days     []PlanDay
+}
+
+func goalSpan(goal model.Goal) quran.Span {
+	switch goal.Scope {
+	case model.GoalScopeSurah:
+		surah, _ := quran.SurahByNumber(goal.ScopeNumber)
+		return surah.Span()
+	case model.GoalScopeJuz:
+		juz, _ := quran.JuzSpan(goal.ScopeNumber)
+		return juz
+	default:
+		return quran.Span{First: 1, Last: quran.TotalAyahs}
+	}
+}
+
+// goalOrder lists the ayahs of the goal's scope in the order they are to be
+// memorized. Going backward reverses the order of the surahs but each surah
+// is still memorized from its first ayah.
+func goalOrder(goal model.Goal) []int {
+	scope := goalSpan(goal)
+	first, _ := quran.PositionAt(scope.First)
+	last, _ := quran.PositionAt(scope.Last)
+
+	var surahs []quran.Span
+	for number := first.Surah; number <= last.Surah; number++ {
+		surah, _ := quran.SurahByNumber(number)
+		span := surah.Span()
+		if span.First < scope.First {
+			span.First = scope.First
+		}
+		if span.Last > scope.Last {
+			span.Last = scope.Last
+		}
+		surahs = append(surahs, span)
+	}
+	if goal.Direction == model.GoalDirectionBackward {
+		for i, j := 0, len(surahs)-1; i < j; i, j = i+1, j-1 {
+			surahs[i], surahs[j] = surahs[j], surahs[i]
+		}
+	}
+
+	order := make([]int, 0, scope.Len())
+	for _, span := range surahs {
+		for index := span.First; index <= span.Last; index++ {
+			order = append(order, index)
+		}
+	}
+	return order
+}
+
+func daysBetween(from, to time.Time) int {
+	return int(to.Sub(from).Hours()/24 + 0.5)
+}
+
+func ceilDiv(a, b int) int {
+	if b <= 0 {
+		return a
+	}
+	return (a + b - 1) / b
+}
+
+func (s *Service) memorizedInScope(userID uint, scope quran.Span) (int, []quran.Span, error) {
+	spans, err := s.repository.GetMemorizedSpans(userID)
+	if err != nil {
+		return 0, nil, err
+	}
+	memorized := 0
+	for _, span := range spans {
+		memorized += span.Intersect(scope)
+	}
+	return memorized, spans, nil
+}
+
+// CreateGoal validates the scope and dates and plans the goal. Either a
+// target date or a daily pace has to be given; the other is derived from it.
+func (s *Service) CreateGoal(user model.User, input GoalInput, now time.Time) (model.Goal, error) {
+	location := user.Location()
+	goal := model.Goal{
+		UserID:    user.ID,
+		Title:     input.Title,
+		Scope:     input.Scope,
+		Direction: input.Direction,
+		DailyPace: input.DailyPace,
+	}
+
+	switch goal.Scope {
+	case model.GoalScopeSurah:
+		surah, ok := quran.LookupSurah(input.Surah)
+		if !ok {
+			return model.Goal{}, fmt.Errorf("%w: unknown surah %q", ErrInvalidGoal, input.Surah)
+		}
+		goal.ScopeNumber = surah.Number
+		if goal.Title == "" {
+			goal.Title = "Memorize " + surah.Name
+		}
+	case model.GoalScopeJuz:
+		if _, ok := quran.JuzSpan(input.Juz); !ok {
+			return model.Goal{}, fmt.Errorf("%w: juz must be between 1 and %d", ErrInvalidGoal, quran.JuzCount)
+		}
+		goal.ScopeNumber = input.Juz
+		if goal.Title == "" {
+			goal.Title = fmt.Sprintf("Memorize Juz %d", input.Juz)
+		}
+	case model.GoalScopeQuran:
+		if goal.Title == "" {
+			goal.Title = "Memorize the whole Quran"
+		}
+	default:
+		return model.Goal{}, fmt.Errorf("%w: scope must be surah, juz or quran", ErrInvalidGoal)
+	}
+
+	switch goal.Direction {
+	case "":
+		goal.Direction = model.GoalDirectionForward
+	case model.GoalDirectionForward, model.GoalDirectionBackward:
+	default:
+		return model.Goal{}, fmt.Errorf("%w: direction must be forward or backward", ErrInvalidGoal)
+	}
+
+	goal.StartDate = localDate(now, location)
+	if input.StartDate != "" {
+		start, err := time.ParseInLocation(dateLayout, input.StartDate, location)
+		if err != nil {
+			return model.Goal{}, fmt.Errorf("%w: startDate must be a YYYY-MM-DD date", ErrInvalidGoal)
+		}
+		goal.StartDate = start
+	}
+
+	if goal.DailyPace < 0 {
+		return model.Goal{}, fmt.Errorf("%w: dailyPace can't be negative", ErrInvalidGoal)
+	}
+	if input.TargetDate != "" {
+		target, err := time.ParseInLocation(dateLayout, input.TargetDate, location)
+		if err != nil {
+			return model.Goal{}, fmt.Errorf("%w: targetDate must be a YYYY-MM-DD date", ErrInvalidGoal)
+		}
+		if target.Before(goal.StartDate) {
+			return model.Goal{}, fmt.Errorf("%w: targetDate is before startDate", ErrInvalidGoal)
+		}
+		goal.TargetDate = target
+	} else if goal.DailyPace == 0 {
+		return model.Goal{}, fmt.Errorf("%w: either targetDate or dailyPace is required", ErrInvalidGoal)
+	}
+	goal.PaceFixed = input.TargetDate == ""
+
+	memorized, _, err := s.memorizedInScope(user.ID, goalSpan(goal))
+	if err != nil {
+		return model.Goal{}, err
+	}
+	plan(&goal, goal.StartDate, memorized)
+
+	id, err := s.repository.AddGoal(goal)
+	if err != nil {
+		return model.Goal{}, err
+	}
+	goal.ID = id
+	return goal, nil
+}
+
+// plan spreads the ayahs left after memorized over the days from start to
+// the target date. When the pace is fixed the target date moves instead.
+func plan(goal *model.Goal, start time.Time, memorized int) {
+	remaining := goalSpan(*goal).Len() - memorized
+	goal.PlanStartDate = start
+	goal.BaselineAyahs = memorized
+
+	if goal.PaceFixed {
+		days := ceilDiv(remaining, goal.DailyPace)
+		if days < 1 {
+			days = 1
+		}
+		goal.TargetDate = start.AddDate(0, 0, days-1)
+		return
+	}
+
+	days := daysBetween(start, goal.TargetDate) + 1
+	if days < 1 {
+		days = 1
+	}
+	goal.DailyPace = ceilDiv(remaining, days)
+}
+
+// plannedBy is how many ayahs of the scope the plan expects to be memorized
+// by the end of day.
+func plannedBy(goal model.Goal, day time.Time) int {
+	total := goalSpan(goal).Len()
+	if day.Before(goal.PlanStartDate) {
+		return goal.BaselineAyahs
+	}
+	planned := goal.BaselineAyahs + goal.DailyPace*(daysBetween(goal.PlanStartDate, day)+1)
+	if planned > total {
+		return total
+	}
+	return planned
+}
+
+// progress compares the goal with what has been memorized, recalculating
+// the plan first if the user finished yesterday behind it.
+func (s *Service) progress(user model.User, goal *model.Goal, now time.Time) (GoalProgress, []quran.Span, error) {
+	scope := goalSpan(*goal)
+	memorized, spans, err := s.memorizedInScope(user.ID, scope)
+	if err != nil {
+		return GoalProgress{}, nil, err
+	}
+
+	today := localDate(now, user.Location())
+	// Dates are stored in UTC by some databases; bring them back to the
+	// user's timezone before comparing days.
+	goal.StartDate = localDate(goal.StartDate, user.Location())
+	goal.TargetDate = localDate(goal.TargetDate, user.Location())
+	goal.PlanStartDate = localDate(goal.PlanStartDate, user.Location())
+
+	total := scope.Len()
+	yesterday := today.AddDate(0, 0, -1)
+	if memorized < total && !today.After(goal.TargetDate) && memorized < plannedBy(*goal, yesterday) {
+		plan(goal, today, memorized)
+		goal.Recalculations++
+		if err := s.repository.UpdateGoal(*goal); err != nil {
+			return GoalProgress{}, nil, err
+		}
+	}
+
+	progress := GoalProgress{
+		Goal:           *goal,
+		TotalAyahs:     total,
+		MemorizedAyahs: memorized,
+		PlannedAyahs:   plannedBy(*goal, today),
+		Percentage:     percentage(memorized, total),
+	}
+	if !today.After(goal.TargetDate) {
+		progress.DaysLeft = daysBetween(today, goal.TargetDate) + 1
+	}
+
+	switch {
+	case memorized >= total:
+		progress.Status = GoalStatusAchieved
+	case today.After(goal.TargetDate):
+		progress.Status = GoalStatusMissed
+	case goal.Recalculations > 0 && goal.PlanStartDate.Equal(today) && memorized < progress.PlannedAyahs:
+		// The plan was just stretched because yesterday ended behind it.
+		progress.Status = GoalStatusBehind
+	case memorized > progress.PlannedAyahs:
+		progress.Status = GoalStatusAhead
+	default:
+		// Today's portion may still be pending.
+		progress.Status = GoalStatusOnTrack
+	}
+	return progress, spans, nil
+}
+
+func (s *Service) getOwnGoal(user model.User, goalID uint) (model.Goal, error) {
+	goal, err := s.repository.GetGoalByID(goalID)
+	if err != nil {
+		return model.Goal{}, err
+	}
+	if goal.ID == 0 || goal.UserID != user.ID {
+		return model.Goal{}, ErrGoalNotFound
+	}
+	return goal, nil
+}
+
+func (s *Service) GetGoals(user model.User, now time.Time) ([]GoalProgress, error) {
+	goals, err := s.repository.GetGoalsByUser(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]GoalProgress, 0, len(goals))
+	for i := range goals {
+		progress, _, err := s.progress(user, &goals[i], now)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, progress)
+	}
+	return result, nil
+}
+
+func (s *Service) GetGoal(user model.User, goalID uint, now time.Time) (GoalProgress, error) {
+	goal, err := s.getOwnGoal(user, goalID)
+	if err != nil {
+		return GoalProgress{}, err
+	}
+	progress, _, err := s.progress(user, &goal, now)
+	return progress, err
+}
+
+// GetGoalPlan returns the day-by-day schedule of the ayahs still to be
+// memorized, from today until the target date.
+func (s *Service) GetGoalPlan(user model.User, goalID uint, now time.Time) (GoalPlan, error) {
+	goal, err := s.getOwnGoal(user, goalID)
+	if err != nil {
+		return GoalPlan{}, err
+	}
+	progress, spans, err := s.progress(user, &goal, now)
+	if err != nil {
+		return GoalPlan{}, err
+	}
+
+	result := GoalPlan{Progress: progress, Days: []PlanDay{}}
+	if progress.Status == GoalStatusAchieved || progress.Status == GoalStatusMissed {
+		return result, nil
+	}
+
+	isMemorized := func(index int) bool {
+		for _, span := range spans {
+			if span.First <= index && index <= span.Last {
+				return true
+			}
+		}
+		return false
+	}
+	var remaining []int
+	for _, index := range goalOrder(goal) {
+		if !isMemorized(index) {
+			remaining = append(remaining, index)
+		}
+	}
+
+	today := localDate(now, user.Location())
+	day := goal.PlanStartDate
+	if day.Before(today) {
+		day = today
+	}
+	planned := progress.MemorizedAyahs
+	for len(remaining) > 0 && !day.After(goal.TargetDate) {
+		// Today's portion shrinks by what's already been done today.
+		portion := plannedBy(goal, day) - planned
+		if portion <= 0 {
+			day = day.AddDate(0, 0, 1)
+			continue
+		}
+		if portion > len(remaining) {
+			portion = len(remaining)
+		}
+		planned += portion
+		result.Days = append(result.Days, PlanDay{
+			Date:         day.Format(dateLayout),
+			Segments:     segments(remaining[:portion]),
+			Ayahs:        portion,
+			PlannedTotal: planned,
+		})
+		remaining = remaining[portion:]
+		day = day.AddDate(0, 0, 1)
+	}
+	return result, nil
+}
+
+// segments groups consecutive ayah indices of the same surah.
+func segments(indices []int) []PlanSegment {
+	var result []PlanSegment
+	for i := 0; i < len(indices); {
+		start, _ := quran.PositionAt(indices[i])
+		j := i
+		for j+1 < len(indices) && indices[j+1] == indices[j]+1 {
+			if next, _ := quran.PositionAt(indices[j+1]); next.Surah != start.Surah {
+				break
+			}
+			j++
+		}
+		end, _ := quran.PositionAt(indices[j])
+		surah, _ := quran.SurahByNumber(start.Surah)
+		result = append(result, PlanSegment{
+			Surah:     surah.Number,
+			SurahName: surah.Name,
+			AyahRange: quran.AyahRange{Start: start.Ayah, End: end.Ayah}.String(),
+		})
+		i = j + 1
+	}
+	return result
+}
+
+func (s *Service) DeleteGoal(user model.User, goalID uint) error {
+	goal, err := s.getOwnGoal(user, goalID)
+	if err != nil {
+		return err
+	}
+	return s.repository.DeleteGoal(goal.ID)
+}
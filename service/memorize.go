@@ -0,0 +1,229 @@
+package service
+
+import (
+	"a21hc3NpZ25tZW50/metrics"
+	"a21hc3NpZ25tZW50/model"
+	"a21hc3NpZ25tZW50/quran"
+	"a21hc3NpZ25tZW50/repository"
+	"a21hc3NpZ25tZW50/review"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+var ErrInvalidMemorize = validationError("invalid memorize record")
+
+// MemorizeInput is a memorize record as sent by a client. The ayahs are given
+// by SurahName and AyahRange, or by a mushaf Page range instead.
+type MemorizeInput struct {
+	SurahName       string `binding:"required_without=Page,omitempty,surah"`
+	AyahRange       string `binding:"required_with=SurahName,omitempty,ayah_range"`
+	Page            string `binding:"omitempty,page_range"`
+	TotalAyah       int    `binding:"min=0,max=6236"`
+	DateStarted     time.Time
+	DateCompleted   time.Time `binding:"omitempty,gtefield=DateStarted"`
+	ReviewFrequency string    `binding:"omitempty,review_frequency"`
+	LastReviewDate  time.Time
+	AccuracyScore   *float64 `binding:"omitempty,accuracy"`
+	NextReviewDate  time.Time
+	Notes           string `binding:"max=1000"`
+}
+
+func (input MemorizeInput) Memorize() model.Memorize {
+	return model.Memorize{
+		SurahName:       input.SurahName,
+		AyahRange:       input.AyahRange,
+		Page:            input.Page,
+		TotalAyah:       input.TotalAyah,
+		DateStarted:     input.DateStarted,
+		DateCompleted:   input.DateCompleted,
+		ReviewFrequency: input.ReviewFrequency,
+		LastReviewDate:  input.LastReviewDate,
+		AccuracyScore:   input.AccuracyScore,
+		NextReviewDate:  input.NextReviewDate,
+		Notes:           input.Notes,
+	}
+}
+
+// PrepareMemorize validates a Memorize record before it is created or
+// updated from previous (the zero value for new records). The review
+// frequency is stored in its canonical form, and NextReviewDate is
+// recomputed whenever LastReviewDate or the frequency changes.
+func PrepareMemorize(memorize *model.Memorize, previous model.Memorize) error {
+	if err := model.ValidateAccuracy(memorize.AccuracyScore); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidMemorize, err)
+	}
+
+	if memorize.ReviewFrequency == "" {
+		return nil
+	}
+	frequency, err := review.ParseFrequency(memorize.ReviewFrequency)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidMemorize, err)
+	}
+	memorize.ReviewFrequency = frequency.String()
+
+	reviewed := !memorize.LastReviewDate.Equal(previous.LastReviewDate)
+	rescheduled := memorize.ReviewFrequency != previous.ReviewFrequency
+	if !memorize.LastReviewDate.IsZero() && (reviewed || rescheduled) {
+		memorize.NextReviewDate = frequency.Next(memorize.LastReviewDate)
+	}
+	return nil
+}
+
+// MemorizesFromPages turns a record given by a mushaf page range into one
+// record per surah on those pages, each a copy of memorize with SurahName,
+// AyahRange and TotalAyah filled in. Records that name a surah are returned
+// as they are.
+func MemorizesFromPages(memorize model.Memorize) ([]model.Memorize, error) {
+	if memorize.Page == "" || memorize.SurahName != "" {
+		return []model.Memorize{memorize}, nil
+	}
+	pages, err := quran.ParsePageRange(memorize.Page)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidMemorize, err)
+	}
+	span, _ := quran.PageRangeSpan(pages)
+
+	var memorizes []model.Memorize
+	for _, part := range quran.SurahSpans(span) {
+		first, _ := quran.PositionAt(part.First)
+		last, _ := quran.PositionAt(part.Last)
+		surah, _ := quran.SurahByNumber(first.Surah)
+		record := memorize
+		record.SurahName = surah.Name
+		record.AyahRange = quran.AyahRange{Start: first.Ayah, End: last.Ayah}.String()
+		record.TotalAyah = part.Len()
+		record.SetCoverage()
+		memorizes = append(memorizes, record)
+	}
+	return memorizes, nil
+}
+
+func (s *Service) GetMemorizes(user model.User) ([]model.Memorize, error) {
+	return s.repository.GetMemorizesByUserID(user.ID)
+}
+
+// GetMemorize returns one of the user's memorize records. Another user's
+// record is ErrMemorizeNotFound, as if it didn't exist.
+func (s *Service) GetMemorize(user model.User, memorizeID uint) (model.Memorize, error) {
+	memorize, err := s.findMemorize(memorizeID)
+	if err != nil {
+		return model.Memorize{}, err
+	}
+	if memorize.UserID != user.ID {
+		return model.Memorize{}, ErrMemorizeNotFound
+	}
+	return memorize, nil
+}
+
+// findMemorize returns the memorize record whoever it belongs to, or
+// ErrMemorizeNotFound when there is none with that ID.
+func (s *Service) findMemorize(memorizeID uint) (model.Memorize, error) {
+	memorize, err := s.repository.GetMemorizeByID(memorizeID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return model.Memorize{}, ErrMemorizeNotFound
+	}
+	return memorize, err
+}
+
+// AddMemorizes adds a memorize record for the user, or one per surah when it
+// is given by page range, and links each to the assignments it fulfils.
+func (s *Service) AddMemorizes(user model.User, input MemorizeInput) ([]model.Memorize, error) {
+	memorizes, err := MemorizesFromPages(input.Memorize())
+	if err != nil {
+		return nil, err
+	}
+	for i := range memorizes {
+		if err := PrepareMemorize(&memorizes[i], model.Memorize{}); err != nil {
+			return nil, err
+		}
+	}
+
+	for i := range memorizes {
+		memorizes[i].UserID = user.ID
+		memorizeID, err := s.repository.AddMemorize(memorizes[i])
+		if err != nil {
+			return nil, err
+		}
+		memorizes[i].ID = memorizeID
+		metrics.MemorizesCreated.Inc()
+		s.memorizeChanged(model.Memorize{}, memorizes[i])
+	}
+	return memorizes, nil
+}
+
+// UpdateMemorize replaces the editable fields of a memorize record. A page
+// range can stand in for the surah and ayahs when it is within one surah.
+func (s *Service) UpdateMemorize(user model.User, memorizeID uint, input MemorizeInput) (model.Memorize, error) {
+	memorize, err := s.GetMemorize(user, memorizeID)
+	if err != nil {
+		return model.Memorize{}, err
+	}
+
+	memorizes, err := MemorizesFromPages(input.Memorize())
+	if err != nil {
+		return model.Memorize{}, err
+	}
+	if len(memorizes) != 1 {
+		return model.Memorize{}, fmt.Errorf("%w: the pages cover more than one surah", ErrInvalidMemorize)
+	}
+	update := memorizes[0]
+
+	previous := memorize
+	memorize.SurahName = update.SurahName
+	memorize.AyahRange = update.AyahRange
+	memorize.TotalAyah = update.TotalAyah
+	memorize.DateStarted = update.DateStarted
+	memorize.DateCompleted = update.DateCompleted
+	memorize.ReviewFrequency = update.ReviewFrequency
+	memorize.LastReviewDate = update.LastReviewDate
+	memorize.AccuracyScore = update.AccuracyScore
+	memorize.NextReviewDate = update.NextReviewDate
+	memorize.Notes = update.Notes
+
+	if err := PrepareMemorize(&memorize, previous); err != nil {
+		return model.Memorize{}, err
+	}
+	if err := s.repository.UpdateMemorize(memorize); err != nil {
+		return model.Memorize{}, err
+	}
+	memorize.AccuracyGrade = model.Grade(memorize.AccuracyScore)
+	memorize.SetCoverage()
+	s.memorizeChanged(previous, memorize)
+	return memorize, nil
+}
+
+// DeleteMemorize deletes one of the user's memorize records along with its
+// recordings, and unlinks it from the assignments it fulfilled.
+func (s *Service) DeleteMemorize(user model.User, memorizeID uint) error {
+	if _, err := s.GetMemorize(user, memorizeID); err != nil {
+		return err
+	}
+	if err := s.repository.DeleteMemorize(memorizeID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrMemorizeNotFound
+		}
+		return err
+	}
+	if err := s.UnlinkMemorize(memorizeID); err != nil {
+		slog.Error("unlinking assignments failed", "memorize_id", memorizeID, "err", err)
+	}
+	if err := s.DeleteMemorizeRecordings(memorizeID); err != nil {
+		slog.Error("deleting recordings failed", "memorize_id", memorizeID, "err", err)
+	}
+	return nil
+}
+
+// memorizeChanged brings assignments and the activity log up to date with a
+// saved memorize record. The record is saved either way, so failures are
+// only logged.
+func (s *Service) memorizeChanged(previous, memorize model.Memorize) {
+	if err := s.SyncAssignments(memorize); err != nil {
+		slog.Error("syncing assignments failed", "memorize_id", memorize.ID, "err", err)
+	}
+	if err := s.RecordMemorizeActivity(previous, memorize); err != nil {
+		slog.Error("recording activity failed", "memorize_id", memorize.ID, "err", err)
+	}
+}
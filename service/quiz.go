@@ -0,0 +1,395 @@
+package service
+
+import (
+	"a21hc3NpZ25tZW50/model"
+	"a21hc3NpZ25tZW50/quran"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrQuizNotFound  = notFoundError("quiz not found")
+	ErrInvalidQuiz   = validationError("invalid quiz")
+	ErrQuizSubmitted = conflictError("quiz already submitted")
+)
+
+const (
+	DefaultQuizQuestions = 10
+	MaxQuizQuestions     = 50
+	// quizOptions is the number of choices of a multiple-choice question.
+	quizOptions = 4
+)
+
+// QuizRetryBelow is the score under which a memorize record is due for
+// review again the day after the quiz, whatever its review frequency.
+var QuizRetryBelow = 60.0
+
+var quizQuestions = map[string]string{
+	model.QuestionContinueAyah: "Which ayah comes next?",
+	model.QuestionWhichSurah:   "Which surah is this ayah from?",
+	model.QuestionAyahNumber:   "Which ayah of %s is this?",
+	model.QuestionMissingWord:  "Which word is missing?",
+}
+
+type QuizInput struct {
+	Questions  int
+	Types      []string
+	MemorizeID *uint
+}
+
+type QuizAnswer struct {
+	QuestionID uint
+	Answer     string
+}
+
+// QuizView is a quiz as shown to its user. Answers are only included once
+// the quiz has been submitted.
+type QuizView struct {
+	ID          uint
+	CreatedAt   time.Time
+	SubmittedAt *time.Time
+	Score       *float64
+	Questions   []QuizQuestionView
+}
+
+type QuizQuestionView struct {
+	ID         uint
+	MemorizeID uint
+	Type       string
+	Question   string
+	Prompt     string
+	Options    []string `json:",omitempty"`
+	Answer     string   `json:",omitempty"`
+	Response   string   `json:",omitempty"`
+	Correct    *bool    `json:",omitempty"`
+}
+
+func viewQuiz(quiz model.Quiz) QuizView {
+	view := QuizView{
+		ID:          quiz.ID,
+		CreatedAt:   quiz.CreatedAt,
+		SubmittedAt: quiz.SubmittedAt,
+		Score:       quiz.Score,
+		Questions:   []QuizQuestionView{},
+	}
+	for _, question := range quiz.Questions {
+		questionView := QuizQuestionView{
+			ID:         question.ID,
+			MemorizeID: question.MemorizeID,
+			Type:       question.Type,
+			Question:   question.Question,
+			Prompt:     question.Prompt,
+		}
+		if question.Options != "" {
+			questionView.Options = strings.Split(question.Options, "\n")
+		}
+		if quiz.SubmittedAt != nil {
+			questionView.Answer = question.Answer
+			questionView.Response = question.Response
+			questionView.Correct = question.Correct
+		}
+		view.Questions = append(view.Questions, questionView)
+	}
+	return view
+}
+
+// quizAyah is an ayah of a memorize record that has text to ask about.
+type quizAyah struct {
+	memorize model.Memorize
+	index    int
+	verse    quran.Verse
+}
+
+// GenerateQuiz picks random ayahs from the user's completed memorize records
+// and asks a random question of the requested types about each of them.
+// Only ayahs whose text is available can be asked about.
+func (s *Service) GenerateQuiz(user model.User, input QuizInput, random *rand.Rand) (QuizView, error) {
+	if input.Questions == 0 {
+		input.Questions = DefaultQuizQuestions
+	}
+	if input.Questions < 1 || input.Questions > MaxQuizQuestions {
+		return QuizView{}, fmt.Errorf("%w: questions must be between 1 and %d", ErrInvalidQuiz, MaxQuizQuestions)
+	}
+	types := input.Types
+	if len(types) == 0 {
+		types = []string{model.QuestionContinueAyah, model.QuestionWhichSurah, model.QuestionAyahNumber, model.QuestionMissingWord}
+	}
+	for _, questionType := range types {
+		if _, ok := quizQuestions[questionType]; !ok {
+			return QuizView{}, fmt.Errorf("%w: unknown question type %q", ErrInvalidQuiz, questionType)
+		}
+	}
+
+	memorizes, err := s.repository.GetMemorizesByUserID(user.ID)
+	if err != nil {
+		return QuizView{}, err
+	}
+	var ayahs []quizAyah
+	surahs := map[string]bool{}
+	for _, memorize := range memorizes {
+		if !memorize.IsCompleted() || (input.MemorizeID != nil && memorize.ID != *input.MemorizeID) {
+			continue
+		}
+		span, ok := memorize.Span()
+		if !ok {
+			continue
+		}
+		for index := span.First; index <= span.Last; index++ {
+			position, _ := quran.PositionAt(index)
+			if verse, err := quran.Text.Ayah(position); err == nil {
+				ayahs = append(ayahs, quizAyah{memorize: memorize, index: index, verse: verse})
+				surah, _ := quran.SurahByNumber(position.Surah)
+				surahs[surah.Name] = true
+			}
+		}
+	}
+	if len(ayahs) == 0 {
+		return QuizView{}, fmt.Errorf("%w: there are no completed memorize records with text to ask about", ErrInvalidQuiz)
+	}
+
+	quiz := model.Quiz{UserID: user.ID}
+	asked := map[string]bool{}
+	// Some questions can't be asked about some ayahs, e.g. the last ayah of
+	// a record has no next ayah, so give up after enough misses.
+	for attempts := 0; len(quiz.Questions) < input.Questions && attempts < input.Questions*20; attempts++ {
+		ayah := ayahs[random.Intn(len(ayahs))]
+		questionType := types[random.Intn(len(types))]
+		key := fmt.Sprintf("%s:%d", questionType, ayah.index)
+		if asked[key] {
+			continue
+		}
+		question, ok := askQuestion(questionType, ayah, ayahs, surahs, random)
+		if !ok {
+			continue
+		}
+		asked[key] = true
+		quiz.Questions = append(quiz.Questions, question)
+	}
+	if len(quiz.Questions) == 0 {
+		return QuizView{}, fmt.Errorf("%w: no questions of the requested types can be asked about the memorized ayahs", ErrInvalidQuiz)
+	}
+
+	id, err := s.repository.AddQuiz(quiz)
+	if err != nil {
+		return QuizView{}, err
+	}
+	quiz, err = s.repository.GetQuizByID(id)
+	if err != nil {
+		return QuizView{}, err
+	}
+	return viewQuiz(quiz), nil
+}
+
+func askQuestion(questionType string, ayah quizAyah, ayahs []quizAyah, surahs map[string]bool, random *rand.Rand) (model.QuizQuestion, bool) {
+	surah, _ := quran.SurahByNumber(ayah.verse.Surah)
+	question := model.QuizQuestion{
+		MemorizeID: ayah.memorize.ID,
+		Type:       questionType,
+		Question:   quizQuestions[questionType],
+		Prompt:     ayah.verse.Text,
+		SurahName:  surah.Name,
+		Ayah:       ayah.verse.Ayah,
+	}
+
+	var distractors []string
+	switch questionType {
+	case model.QuestionContinueAyah:
+		next, ok := quran.PositionAt(ayah.index + 1)
+		span, _ := ayah.memorize.Span()
+		if !ok || ayah.index+1 > span.Last {
+			return model.QuizQuestion{}, false
+		}
+		verse, err := quran.Text.Ayah(next)
+		if err != nil {
+			return model.QuizQuestion{}, false
+		}
+		question.Answer = verse.Text
+		for _, other := range ayahs {
+			if other.index != ayah.index {
+				distractors = append(distractors, other.verse.Text)
+			}
+		}
+	case model.QuestionWhichSurah:
+		question.Answer = surah.Name
+		for name := range surahs {
+			distractors = append(distractors, name)
+		}
+		// Fill up with other surahs when few have been memorized.
+		for len(distractors) < quizOptions*2 {
+			other, _ := quran.SurahByNumber(random.Intn(len(quran.Surahs)) + 1)
+			distractors = append(distractors, other.Name)
+		}
+	case model.QuestionAyahNumber:
+		question.Question = fmt.Sprintf(question.Question, surah.Name)
+		question.Answer = strconv.Itoa(ayah.verse.Ayah)
+		return question, true
+	case model.QuestionMissingWord:
+		words := strings.Fields(ayah.verse.Text)
+		if len(words) < 2 {
+			return model.QuizQuestion{}, false
+		}
+		missing := random.Intn(len(words))
+		question.Answer = words[missing]
+		words[missing] = "_____"
+		question.Prompt = strings.Join(words, " ")
+		// Words still visible in the prompt would be obviously wrong choices.
+		inPrompt := map[string]bool{}
+		for _, word := range words {
+			inPrompt[word] = true
+		}
+		for _, other := range ayahs {
+			for _, word := range strings.Fields(other.verse.Text) {
+				if !inPrompt[word] {
+					distractors = append(distractors, word)
+				}
+			}
+		}
+	}
+
+	options := chooseOptions(question.Answer, distractors, random)
+	if len(options) < 2 {
+		return model.QuizQuestion{}, false
+	}
+	question.Options = strings.Join(options, "\n")
+	return question, true
+}
+
+// chooseOptions shuffles the answer in with up to quizOptions-1 distinct
+// distractors.
+func chooseOptions(answer string, distractors []string, random *rand.Rand) []string {
+	options := []string{answer}
+	seen := map[string]bool{answer: true}
+	random.Shuffle(len(distractors), func(i, j int) {
+		distractors[i], distractors[j] = distractors[j], distractors[i]
+	})
+	for _, distractor := range distractors {
+		if len(options) == quizOptions {
+			break
+		}
+		if !seen[distractor] {
+			seen[distractor] = true
+			options = append(options, distractor)
+		}
+	}
+	random.Shuffle(len(options), func(i, j int) {
+		options[i], options[j] = options[j], options[i]
+	})
+	return options
+}
+
+func (s *Service) getOwnQuiz(user model.User, quizID uint) (model.Quiz, error) {
+	quiz, err := s.repository.GetQuizByID(quizID)
+	if err != nil {
+		return model.Quiz{}, err
+	}
+	if quiz.ID == 0 || quiz.UserID != user.ID {
+		return model.Quiz{}, ErrQuizNotFound
+	}
+	return quiz, nil
+}
+
+func (s *Service) GetQuiz(user model.User, quizID uint) (QuizView, error) {
+	quiz, err := s.getOwnQuiz(user, quizID)
+	if err != nil {
+		return QuizView{}, err
+	}
+	return viewQuiz(quiz), nil
+}
+
+// GetQuizzes lists the user's quizzes, newest first, without their questions.
+func (s *Service) GetQuizzes(user model.User) ([]QuizView, error) {
+	quizzes, err := s.repository.GetQuizzesByUser(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	views := make([]QuizView, 0, len(quizzes))
+	for _, quiz := range quizzes {
+		views = append(views, viewQuiz(quiz))
+	}
+	return views, nil
+}
+
+// SubmitQuiz grades the answers; unanswered questions count as wrong. Every
+// memorize record the quiz asked about is marked as reviewed, its accuracy
+// set to the share of its questions answered correctly, and it is due again
+// tomorrow when that is below QuizRetryBelow.
+func (s *Service) SubmitQuiz(user model.User, quizID uint, answers []QuizAnswer, now time.Time) (QuizView, error) {
+	quiz, err := s.getOwnQuiz(user, quizID)
+	if err != nil {
+		return QuizView{}, err
+	}
+	if quiz.SubmittedAt != nil {
+		return QuizView{}, ErrQuizSubmitted
+	}
+
+	responses := map[uint]string{}
+	for _, answer := range answers {
+		responses[answer.QuestionID] = answer.Answer
+	}
+
+	type tally struct{ correct, total int }
+	records := map[uint]*tally{}
+	var order []uint
+	correct := 0
+	for i := range quiz.Questions {
+		question := &quiz.Questions[i]
+		question.Response = strings.TrimSpace(responses[question.ID])
+		isCorrect := question.Response == question.Answer
+		if question.Type == model.QuestionAyahNumber {
+			number, err := strconv.Atoi(question.Response)
+			isCorrect = err == nil && strconv.Itoa(number) == question.Answer
+		}
+		question.Correct = &isCorrect
+
+		if records[question.MemorizeID] == nil {
+			records[question.MemorizeID] = &tally{}
+			order = append(order, question.MemorizeID)
+		}
+		records[question.MemorizeID].total++
+		if isCorrect {
+			correct++
+			records[question.MemorizeID].correct++
+		}
+	}
+	score := percentage(correct, len(quiz.Questions))
+	quiz.Score = &score
+	quiz.SubmittedAt = &now
+	if err := s.repository.UpdateQuiz(quiz); err != nil {
+		return QuizView{}, err
+	}
+
+	for _, memorizeID := range order {
+		memorize, err := s.findMemorize(memorizeID)
+		if errors.Is(err, ErrMemorizeNotFound) {
+			// The record was deleted after the quiz was generated.
+			continue
+		}
+		if err != nil {
+			return QuizView{}, err
+		}
+
+		previous := memorize
+		recordScore := percentage(records[memorizeID].correct, records[memorizeID].total)
+		memorize.AccuracyScore = &recordScore
+		memorize.LastReviewDate = now
+		if err := PrepareMemorize(&memorize, previous); err != nil {
+			return QuizView{}, err
+		}
+		tomorrow := localDate(now, user.Location()).AddDate(0, 0, 1)
+		if recordScore < QuizRetryBelow && (memorize.NextReviewDate.IsZero() || memorize.NextReviewDate.After(tomorrow)) {
+			memorize.NextReviewDate = tomorrow
+		}
+		if err := s.repository.UpdateMemorize(memorize); err != nil {
+			return QuizView{}, err
+		}
+		if err := s.RecordMemorizeActivity(previous, memorize); err != nil {
+			return QuizView{}, err
+		}
+	}
+
+	return viewQuiz(quiz), nil
+}
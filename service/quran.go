@@ -0,0 +1,57 @@
+package service
+
+import (
+	"a21hc3NpZ25tZW50/model"
+	"a21hc3NpZ25tZW50/quran"
+	"fmt"
+)
+
+var ErrSurahNotFound = notFoundError("surah not found")
+
+type QuranText struct {
+	Surah     int
+	SurahName string
+	AyahRange string
+	Ayahs     []quran.Verse
+}
+
+// GetQuranText looks up the text of a surah by number or name. An empty
+// ayahs returns the whole surah.
+func GetQuranText(surahName, ayahs string) (QuranText, error) {
+	surah, ok := quran.LookupSurah(surahName)
+	if !ok {
+		return QuranText{}, fmt.Errorf("%w: %q", ErrSurahNotFound, surahName)
+	}
+
+	ayahRange := quran.AyahRange{Start: 1, End: surah.AyahCount}
+	if ayahs != "" {
+		var err error
+		ayahRange, err = quran.ParseAyahRange(ayahs)
+		if err != nil {
+			return QuranText{}, err
+		}
+		if err := surah.Validate(ayahRange); err != nil {
+			return QuranText{}, err
+		}
+	}
+
+	verses, err := quran.Text.Span(surah.SpanOf(ayahRange))
+	if err != nil {
+		return QuranText{}, err
+	}
+	return QuranText{
+		Surah:     surah.Number,
+		SurahName: surah.Name,
+		AyahRange: ayahRange.String(),
+		Ayahs:     verses,
+	}, nil
+}
+
+// MemorizeText returns the text of the ayahs of a memorize record.
+func MemorizeText(memorize model.Memorize) ([]quran.Verse, error) {
+	span, ok := memorize.Span()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s %s", quran.ErrTextUnavailable, memorize.SurahName, memorize.AyahRange)
+	}
+	return quran.Text.Span(span)
+}
@@ -0,0 +1,241 @@
+package service
+
+import (
+	"a21hc3NpZ25tZW50/model"
+	"a21hc3NpZ25tZW50/quran"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrReadingNotFound = notFoundError("reading not found")
+	ErrInvalidReading  = validationError("invalid reading")
+)
+
+// ReadingInput describes a tilawah session. Surahs are given by name or
+// number; EndSurah defaults to StartSurah and Date to today.
+type ReadingInput struct {
+	StartSurah string
+	StartAyah  int
+	EndSurah   string
+	EndAyah    int
+	Pages      int
+	Duration   int
+	Date       string
+	Notes      string
+}
+
+// ReadingPosition is where the next reading picks up.
+type ReadingPosition struct {
+	Surah     int
+	SurahName string
+	Ayah      int
+}
+
+type ReadingStats struct {
+	Readings       int
+	Khatams        int
+	LastKhatamDate string
+	TotalAyahs     int
+	TotalPages     int
+	TotalMinutes   int
+	// KhatamProgress is how much of the khatam under way has been read,
+	// as a percentage of the ayahs of the mushaf.
+	KhatamProgress float64
+	NextPosition   *ReadingPosition
+}
+
+func readingPosition(surahName string, ayah int, field string) (quran.Position, error) {
+	surah, ok := quran.LookupSurah(surahName)
+	if !ok {
+		return quran.Position{}, fmt.Errorf("%w: unknown %s surah %q", ErrInvalidReading, field, surahName)
+	}
+	if ayah < 1 || ayah > surah.AyahCount {
+		return quran.Position{}, fmt.Errorf("%w: %s has %d ayahs, %s ayah %d doesn't exist", ErrInvalidReading, surah.Name, surah.AyahCount, field, ayah)
+	}
+	return quran.Position{Surah: surah.Number, Ayah: ayah}, nil
+}
+
+// readingFromInput validates a reading and works out how many ayahs it
+// covers. Whether it completes a khatam depends on the readings before it,
+// see markKhatams.
+func readingFromInput(user model.User, input ReadingInput, now time.Time) (model.Reading, error) {
+	start, err := readingPosition(input.StartSurah, input.StartAyah, "start")
+	if err != nil {
+		return model.Reading{}, err
+	}
+	if input.EndSurah == "" {
+		input.EndSurah = input.StartSurah
+	}
+	end, err := readingPosition(input.EndSurah, input.EndAyah, "end")
+	if err != nil {
+		return model.Reading{}, err
+	}
+	if input.Pages < 0 {
+		return model.Reading{}, fmt.Errorf("%w: pages can't be negative", ErrInvalidReading)
+	}
+	if input.Duration < 0 {
+		return model.Reading{}, fmt.Errorf("%w: duration can't be negative", ErrInvalidReading)
+	}
+
+	location := user.Location()
+	today := localDate(now, location)
+	date := today
+	if input.Date != "" {
+		if date, err = time.ParseInLocation(dateLayout, input.Date, location); err != nil {
+			return model.Reading{}, fmt.Errorf("%w: date must be a YYYY-MM-DD date", ErrInvalidReading)
+		}
+		if date.After(today) {
+			return model.Reading{}, fmt.Errorf("%w: date is in the future", ErrInvalidReading)
+		}
+	}
+
+	reading := model.Reading{
+		UserID:     user.ID,
+		StartSurah: start.Surah,
+		StartAyah:  start.Ayah,
+		EndSurah:   end.Surah,
+		EndAyah:    end.Ayah,
+		Pages:      input.Pages,
+		Duration:   input.Duration,
+		Date:       date,
+		Notes:      input.Notes,
+	}
+	first, last := start.Index(), end.Index()
+	if last >= first {
+		reading.Ayahs = last - first + 1
+	} else {
+		// Past An-Nas and on from Al-Fatiha.
+		reading.Ayahs = quran.TotalAyahs - first + 1 + last
+	}
+	return reading, nil
+}
+
+// markKhatams works out which of a user's readings, oldest first, complete
+// a khatam and returns how many ayahs of the next one have been read.
+//
+// A khatam is read in mushaf order from wherever the first reading starts.
+// A reading that starts at or before the furthest ayah reached carries the
+// khatam on to where it ends; going over ayahs already read, such as Juz
+// 'Amma every night, doesn't. The khatam is complete once every ayah from
+// its start has been read, and the next one starts there again.
+func markKhatams(readings []model.Reading) int {
+	var start, covered int
+	for i := range readings {
+		reading := &readings[i]
+		first := quran.Position{Surah: reading.StartSurah, Ayah: reading.StartAyah}.Index()
+		if i == 0 {
+			start = first
+		}
+		// How many ayahs into the khatam the reading starts.
+		offset := (first - start + quran.TotalAyahs) % quran.TotalAyahs
+		if offset <= covered && offset+reading.Ayahs > covered {
+			covered = offset + reading.Ayahs
+		}
+		reading.Khatam = covered >= quran.TotalAyahs
+		if reading.Khatam {
+			covered -= quran.TotalAyahs
+		}
+	}
+	return covered
+}
+
+func (s *Service) AddReading(user model.User, input ReadingInput, now time.Time) (model.Reading, error) {
+	reading, err := readingFromInput(user, input, now)
+	if err != nil {
+		return model.Reading{}, err
+	}
+	reading, err = s.repository.AddReading(reading)
+	if err != nil {
+		return model.Reading{}, err
+	}
+	return s.GetReading(user, reading.ID)
+}
+
+func (s *Service) GetReadings(user model.User) ([]model.Reading, error) {
+	readings, _, err := s.getReadings(user)
+	return readings, err
+}
+
+// getReadings also returns how many ayahs of the khatam under way have been
+// read.
+func (s *Service) getReadings(user model.User) ([]model.Reading, int, error) {
+	readings, err := s.repository.GetReadingsByUser(user.ID)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range readings {
+		readings[i].Date = localDate(readings[i].Date, user.Location())
+	}
+	covered := markKhatams(readings)
+	return readings, covered, nil
+}
+
+// GetReading finds one of the user's readings. All of them are loaded to
+// tell whether it completes a khatam.
+func (s *Service) GetReading(user model.User, readingID uint) (model.Reading, error) {
+	readings, err := s.GetReadings(user)
+	if err != nil {
+		return model.Reading{}, err
+	}
+	for _, reading := range readings {
+		if reading.ID == readingID {
+			return reading, nil
+		}
+	}
+	return model.Reading{}, ErrReadingNotFound
+}
+
+func (s *Service) UpdateReading(user model.User, readingID uint, input ReadingInput, now time.Time) (model.Reading, error) {
+	existing, err := s.GetReading(user, readingID)
+	if err != nil {
+		return model.Reading{}, err
+	}
+	reading, err := readingFromInput(user, input, now)
+	if err != nil {
+		return model.Reading{}, err
+	}
+	reading.Model = existing.Model
+	if err := s.repository.UpdateReading(reading); err != nil {
+		return model.Reading{}, err
+	}
+	return s.GetReading(user, reading.ID)
+}
+
+func (s *Service) DeleteReading(user model.User, readingID uint) error {
+	reading, err := s.GetReading(user, readingID)
+	if err != nil {
+		return err
+	}
+	return s.repository.DeleteReading(reading.ID)
+}
+
+// GetReadingStats totals a user's readings and counts the khatams completed.
+// The next position follows on from the most recent reading.
+func (s *Service) GetReadingStats(user model.User) (ReadingStats, error) {
+	readings, covered, err := s.getReadings(user)
+	if err != nil {
+		return ReadingStats{}, err
+	}
+
+	stats := ReadingStats{Readings: len(readings)}
+	for _, reading := range readings {
+		stats.TotalAyahs += reading.Ayahs
+		stats.TotalPages += reading.Pages
+		stats.TotalMinutes += reading.Duration
+		if reading.Khatam {
+			stats.Khatams++
+			stats.LastKhatamDate = reading.Date.Format(dateLayout)
+		}
+	}
+
+	if len(readings) > 0 {
+		last := readings[len(readings)-1]
+		index := quran.Position{Surah: last.EndSurah, Ayah: last.EndAyah}.Index()
+		stats.KhatamProgress = percentage(covered, quran.TotalAyahs)
+		next, _ := quran.PositionAt(index%quran.TotalAyahs + 1)
+		surah, _ := quran.SurahByNumber(next.Surah)
+		stats.NextPosition = &ReadingPosition{Surah: next.Surah, SurahName: surah.Name, Ayah: next.Ayah}
+	}
+	return stats, nil
+}
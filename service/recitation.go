@@ -0,0 +1,80 @@
+package service
+
+import (
+	"a21hc3NpZ25tZW50/model"
+	"a21hc3NpZ25tZW50/quran"
+	"a21hc3NpZ25tZW50/recitation"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrMemorizeNotFound  = notFoundError("memorize record not found")
+	ErrInvalidRecitation = validationError("invalid recitation")
+)
+
+type RecitationInput struct {
+	Text string `binding:"max=100000"`
+	// AyahRange optionally limits the check to part of the record.
+	AyahRange string
+}
+
+type RecitationCheck struct {
+	MemorizeID uint
+	SurahName  string
+	AyahRange  string
+	recitation.Result
+}
+
+// maxTypedWords is how many words a recitation checked against expected
+// words may have. Aligning them takes time for every pair of words, so a text
+// far longer than the ayahs is turned away.
+func maxTypedWords(expected int) int {
+	return 2*expected + 20
+}
+
+// CheckRecitation compares what the user typed from memory with the text of
+// the ayahs of a memorize record.
+func (s *Service) CheckRecitation(user model.User, memorizeID uint, input RecitationInput) (RecitationCheck, error) {
+	memorize, err := s.GetMemorize(user, memorizeID)
+	if err != nil {
+		return RecitationCheck{}, err
+	}
+	if strings.TrimSpace(input.Text) == "" {
+		return RecitationCheck{}, fmt.Errorf("%w: text is required", ErrInvalidRecitation)
+	}
+
+	surah, ok := quran.LookupSurah(memorize.SurahName)
+	if !ok {
+		return RecitationCheck{}, fmt.Errorf("%w: unknown surah %q", ErrInvalidRecitation, memorize.SurahName)
+	}
+	ayahs, err := quran.ParseAyahRange(memorize.AyahRange)
+	if err != nil || surah.Validate(ayahs) != nil {
+		return RecitationCheck{}, fmt.Errorf("%w: the record's ayah range %q is invalid", ErrInvalidRecitation, memorize.AyahRange)
+	}
+	if input.AyahRange != "" {
+		part, err := quran.ParseAyahRange(input.AyahRange)
+		if err != nil || !ayahs.Contains(part) {
+			return RecitationCheck{}, fmt.Errorf("%w: ayahRange must be within %s", ErrInvalidRecitation, ayahs)
+		}
+		ayahs = part
+	}
+
+	verses, err := quran.Text.Span(surah.SpanOf(ayahs))
+	if err != nil {
+		return RecitationCheck{}, err
+	}
+	expected := 0
+	for _, verse := range verses {
+		expected += len(recitation.Words(verse.Text))
+	}
+	if typed := len(recitation.Words(input.Text)); typed > maxTypedWords(expected) {
+		return RecitationCheck{}, fmt.Errorf("%w: the text has %d words, more than the %d allowed for %d expected words", ErrInvalidRecitation, typed, maxTypedWords(expected), expected)
+	}
+	return RecitationCheck{
+		MemorizeID: memorize.ID,
+		SurahName:  surah.Name,
+		AyahRange:  ayahs.String(),
+		Result:     recitation.Check(verses, input.Text),
+	}, nil
+}
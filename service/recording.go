@@ -0,0 +1,204 @@
+package service
+
+import (
+	"a21hc3NpZ25tZW50/audio"
+	"a21hc3NpZ25tZW50/model"
+	"a21hc3NpZ25tZW50/storage"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"time"
+)
+
+var (
+	ErrRecordingNotFound = notFoundError("recording not found")
+	ErrInvalidRecording  = validationError("invalid recording")
+	ErrRecordingTooLarge = errors.New("recording too large")
+)
+
+// DefaultRecordingsDir is where recordings are kept on the local disk unless
+// another file store is set.
+const DefaultRecordingsDir = "recordings"
+
+// Limits on uploaded recordings. Files whose duration can't be read are
+// turned away, as they can't be held to MaxRecordingDuration.
+var (
+	MaxRecordingSize     int64 = 20 << 20
+	MaxRecordingDuration       = 10 * time.Minute
+)
+
+type RecordingUpload struct {
+	FileName string
+	Size     int64
+	Content  io.ReadSeeker
+}
+
+// SetFileStore sets where uploaded recordings are kept.
+func (s *Service) SetFileStore(store storage.FileStore) {
+	s.fileStore = store
+}
+
+// getViewableMemorize allows the owner of a memorize record and the teachers
+// of its owner to see it. Others are told it doesn't exist.
+func (s *Service) getViewableMemorize(user model.User, memorizeID uint) (model.Memorize, error) {
+	memorize, err := s.findMemorize(memorizeID)
+	if err != nil {
+		return model.Memorize{}, err
+	}
+	if err := s.canViewStudent(user, memorize.UserID); err != nil {
+		if errors.Is(err, ErrNotTeacher) || errors.Is(err, ErrNotYourStudent) {
+			return model.Memorize{}, ErrMemorizeNotFound
+		}
+		return model.Memorize{}, err
+	}
+	return memorize, nil
+}
+
+func randomName() (string, error) {
+	name := make([]byte, 16)
+	if _, err := rand.Read(name); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(name), nil
+}
+
+// AddRecording checks that the upload is audio in one of the supported
+// formats and within the limits, and stores it for a memorize record of the
+// user.
+func (s *Service) AddRecording(user model.User, memorizeID uint, upload RecordingUpload) (model.Recording, error) {
+	memorize, err := s.GetMemorize(user, memorizeID)
+	if err != nil {
+		return model.Recording{}, err
+	}
+	if upload.Size > MaxRecordingSize {
+		return model.Recording{}, fmt.Errorf("%w: recordings can be at most %d MB", ErrRecordingTooLarge, MaxRecordingSize>>20)
+	}
+
+	info, err := audio.Probe(upload.Content)
+	if err != nil {
+		return model.Recording{}, err
+	}
+	if info.Duration <= 0 {
+		return model.Recording{}, fmt.Errorf("%w: the length of the recording can't be read from the file", ErrInvalidRecording)
+	}
+	if info.Duration > MaxRecordingDuration {
+		return model.Recording{}, fmt.Errorf("%w: recordings can be at most %s long", ErrInvalidRecording, MaxRecordingDuration)
+	}
+	if _, err := upload.Content.Seek(0, io.SeekStart); err != nil {
+		return model.Recording{}, err
+	}
+
+	name, err := randomName()
+	if err != nil {
+		return model.Recording{}, err
+	}
+	recording := model.Recording{
+		MemorizeID:  memorize.ID,
+		UserID:      user.ID,
+		FileName:    filepath.Base(upload.FileName),
+		ContentType: info.ContentType(),
+		StorageKey:  fmt.Sprintf("recordings/%d/%s%s", memorize.ID, name, info.Extension()),
+	}
+	duration := info.Duration.Round(time.Millisecond).Seconds()
+	recording.Duration = &duration
+
+	recording.Size, err = s.fileStore.Save(recording.StorageKey, upload.Content)
+	if err != nil {
+		return model.Recording{}, err
+	}
+	saved, err := s.repository.AddRecording(recording)
+	if err != nil {
+		s.fileStore.Delete(recording.StorageKey)
+		return model.Recording{}, err
+	}
+	return saved, nil
+}
+
+func (s *Service) GetRecordings(user model.User, memorizeID uint) ([]model.Recording, error) {
+	memorize, err := s.getViewableMemorize(user, memorizeID)
+	if err != nil {
+		return nil, err
+	}
+	return s.repository.GetRecordingsByMemorize(memorize.ID)
+}
+
+func (s *Service) getViewableRecording(user model.User, recordingID uint) (model.Recording, error) {
+	recording, err := s.repository.GetRecordingByID(recordingID)
+	if err != nil {
+		return model.Recording{}, err
+	}
+	if recording.ID == 0 {
+		return model.Recording{}, ErrRecordingNotFound
+	}
+	if _, err := s.getViewableMemorize(user, recording.MemorizeID); err != nil {
+		if errors.Is(err, ErrMemorizeNotFound) {
+			return model.Recording{}, ErrRecordingNotFound
+		}
+		return model.Recording{}, err
+	}
+	return recording, nil
+}
+
+// OpenRecording returns the recording and its audio, which the caller must
+// close.
+func (s *Service) OpenRecording(user model.User, recordingID uint) (model.Recording, io.ReadSeekCloser, error) {
+	recording, err := s.getViewableRecording(user, recordingID)
+	if err != nil {
+		return model.Recording{}, nil, err
+	}
+	file, err := s.fileStore.Open(recording.StorageKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		return model.Recording{}, nil, fmt.Errorf("%w: the audio file is missing", ErrRecordingNotFound)
+	}
+	if err != nil {
+		return model.Recording{}, nil, err
+	}
+	return recording, file, nil
+}
+
+// DeleteRecording lets students delete their own recordings, together with
+// the comments on them.
+func (s *Service) DeleteRecording(user model.User, recordingID uint) error {
+	recording, err := s.repository.GetRecordingByID(recordingID)
+	if err != nil {
+		return err
+	}
+	if recording.ID == 0 || recording.UserID != user.ID {
+		return ErrRecordingNotFound
+	}
+	if err := s.repository.DeleteRecording(recording.ID); err != nil {
+		return err
+	}
+	if err := s.repository.DeleteCommentsByRecording(recording.ID); err != nil {
+		return err
+	}
+	if err := s.fileStore.Delete(recording.StorageKey); err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return err
+	}
+	return nil
+}
+
+// DeleteMemorizeRecordings removes the recordings of a deleted memorize
+// record.
+func (s *Service) DeleteMemorizeRecordings(memorizeID uint) error {
+	recordings, err := s.repository.GetRecordingsByMemorize(memorizeID)
+	if err != nil {
+		return err
+	}
+	for _, recording := range recordings {
+		if err := s.repository.DeleteRecording(recording.ID); err != nil {
+			return err
+		}
+		if err := s.repository.DeleteCommentsByRecording(recording.ID); err != nil {
+			return err
+		}
+		if err := s.fileStore.Delete(recording.StorageKey); err != nil && !errors.Is(err, storage.ErrNotFound) {
+			slog.Error("deleting recording file failed", "storage_key", recording.StorageKey, "err", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"a21hc3NpZ25tZW50/model"
+	"a21hc3NpZ25tZW50/quran"
+	"fmt"
+	"math"
+	"time"
+)
+
+var ErrInvalidRotation = validationError("invalid rotation")
+
+// MaxRotationDays limits the length of a murajaah cycle.
+const MaxRotationDays = 366
+
+// DefaultRotation is used until the user configures their own cycle: every
+// memorized ayah is revised once a week.
+var DefaultRotation = model.Rotation{Unit: model.RotationUnitAyah, Days: 7}
+
+type RotationInput struct {
+	Unit        string
+	Days        int
+	DailyAmount float64
+	StartDate   string
+}
+
+type RotationDay struct {
+	Day      int
+	Date     string
+	Segments []PlanSegment
+	Ayahs    int
+	// Amount is the size of the portion in the rotation's unit.
+	Amount float64
+}
+
+type RotationPlan struct {
+	Rotation    model.Rotation
+	CycleDays   int
+	TotalAyahs  int
+	TotalAmount float64
+	// CycleNumber and DayOfCycle are zero before the rotation starts.
+	CycleNumber int
+	DayOfCycle  int
+	Today       RotationDay
+	Days        []RotationDay
+}
+
+func round2(value float64) float64 {
+	return math.Round(value*100) / 100
+}
+
+// SetRotation validates and stores the user's murajaah cycle. Either Days or
+// DailyAmount has to be given.
+func (s *Service) SetRotation(user model.User, input RotationInput, now time.Time) (model.Rotation, error) {
+	rotation, err := s.repository.GetRotationByUser(user.ID)
+	if err != nil {
+		return model.Rotation{}, err
+	}
+	rotation.UserID = user.ID
+	rotation.Unit = input.Unit
+	rotation.Days = input.Days
+	rotation.DailyAmount = input.DailyAmount
+
+	switch rotation.Unit {
+	case "":
+		rotation.Unit = model.RotationUnitAyah
+	case model.RotationUnitAyah, model.RotationUnitPage, model.RotationUnitJuz:
+	default:
+		return model.Rotation{}, fmt.Errorf("%w: unit must be ayah, page or juz", ErrInvalidRotation)
+	}
+
+	switch {
+	case rotation.Days != 0 && rotation.DailyAmount != 0:
+		return model.Rotation{}, fmt.Errorf("%w: give either days or dailyAmount, not both", ErrInvalidRotation)
+	case rotation.Days == 0 && rotation.DailyAmount == 0:
+		return model.Rotation{}, fmt.Errorf("%w: either days or dailyAmount is required", ErrInvalidRotation)
+	case rotation.Days < 0 || rotation.Days > MaxRotationDays:
+		return model.Rotation{}, fmt.Errorf("%w: days must be between 1 and %d", ErrInvalidRotation, MaxRotationDays)
+	case rotation.DailyAmount < 0:
+		return model.Rotation{}, fmt.Errorf("%w: dailyAmount can't be negative", ErrInvalidRotation)
+	}
+
+	location := user.Location()
+	rotation.StartDate = localDate(now, location)
+	if input.StartDate != "" {
+		start, err := time.ParseInLocation(dateLayout, input.StartDate, location)
+		if err != nil {
+			return model.Rotation{}, fmt.Errorf("%w: startDate must be a YYYY-MM-DD date", ErrInvalidRotation)
+		}
+		rotation.StartDate = start
+	}
+
+	return s.repository.SaveRotation(rotation)
+}
+
+// GetRotation splits everything the user has completed into the days of
+// their murajaah cycle and works out which day of the cycle today is. The
+// cycle follows the Memorize records, so newly memorized ayahs are revised
+// from the next request on.
+func (s *Service) GetRotation(user model.User, now time.Time) (RotationPlan, error) {
+	rotation, err := s.repository.GetRotationByUser(user.ID)
+	if err != nil {
+		return RotationPlan{}, err
+	}
+	location := user.Location()
+	if rotation.ID == 0 {
+		rotation = DefaultRotation
+		rotation.UserID = user.ID
+		rotation.StartDate = user.CreatedAt
+	}
+	rotation.StartDate = localDate(rotation.StartDate, location)
+
+	spans, err := s.repository.GetMemorizedSpans(user.ID)
+	if err != nil {
+		return RotationPlan{}, err
+	}
+	var indices []int
+	for _, span := range spans {
+		for index := span.First; index <= span.Last; index++ {
+			indices = append(indices, index)
+		}
+	}
+	weights := rotationWeights(rotation.Unit, indices)
+	total := 0.0
+	for _, weight := range weights {
+		total += weight
+	}
+
+	days := rotation.Days
+	if days == 0 {
+		days = int(math.Ceil(total/rotation.DailyAmount - 1e-9))
+	}
+	if days > len(indices) {
+		days = len(indices)
+	}
+	if days < 1 {
+		days = 1
+	}
+
+	result := RotationPlan{
+		Rotation:    rotation,
+		CycleDays:   days,
+		TotalAyahs:  len(indices),
+		TotalAmount: round2(total),
+		Days:        []RotationDay{},
+	}
+
+	today := localDate(now, location)
+	cycleStart := rotation.StartDate
+	if offset := daysBetween(rotation.StartDate, today); offset >= 0 {
+		result.CycleNumber = offset/days + 1
+		result.DayOfCycle = offset%days + 1
+		cycleStart = today.AddDate(0, 0, -(offset % days))
+	}
+
+	start := 0
+	for day, end := range splitBalanced(weights, days) {
+		amount := 0.0
+		for _, weight := range weights[start:end] {
+			amount += weight
+		}
+		result.Days = append(result.Days, RotationDay{
+			Day:      day + 1,
+			Date:     cycleStart.AddDate(0, 0, day).Format(dateLayout),
+			Segments: segments(indices[start:end]),
+			Ayahs:    end - start,
+			Amount:   round2(amount),
+		})
+		start = end
+	}
+
+	result.Today = RotationDay{Date: today.Format(dateLayout), Segments: []PlanSegment{}}
+	if result.DayOfCycle > 0 && result.DayOfCycle <= len(result.Days) {
+		result.Today = result.Days[result.DayOfCycle-1]
+	}
+	return result, nil
+}
+
+// rotationWeights is the size of every ayah in the rotation's unit. In pages
+// or juz every page of the Madinah mushaf, or every juz, weighs the same
+// however many ayahs it has.
+func rotationWeights(unit string, indices []int) []float64 {
+	weights := make([]float64, len(indices))
+	for i, index := range indices {
+		weights[i] = 1
+		position, _ := quran.PositionAt(index)
+		switch unit {
+		case model.RotationUnitPage:
+			page, _ := quran.PageSpan(quran.PageOf(position))
+			weights[i] = 1 / float64(page.Len())
+		case model.RotationUnitJuz:
+			juz, _ := quran.JuzSpan(quran.JuzOf(position))
+			weights[i] = 1 / float64(juz.Len())
+		}
+	}
+	return weights
+}
+
+// splitBalanced cuts weights into days consecutive chunks of about equal
+// weight and returns the end (exclusive) of every chunk. Every chunk gets at
+// least one item; days must not exceed len(weights).
+func splitBalanced(weights []float64, days int) []int {
+	if len(weights) == 0 {
+		return nil
+	}
+	prefix := make([]float64, len(weights)+1)
+	for i, weight := range weights {
+		prefix[i+1] = prefix[i] + weight
+	}
+	total := prefix[len(weights)]
+
+	ends := make([]int, 0, days)
+	previous := 0
+	for day := 1; day < days; day++ {
+		target := total * float64(day) / float64(days)
+		last := len(weights) - (days - day)
+		end := previous + 1
+		for end < last && prefix[end] < target {
+			end++
+		}
+		if end > previous+1 && target-prefix[end-1] < prefix[end]-target {
+			end--
+		}
+		ends = append(ends, end)
+		previous = end
+	}
+	return append(ends, len(weights))
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"a21hc3NpZ25tZW50/model"
+	"a21hc3NpZ25tZW50/quran"
+	"fmt"
+	"math"
+)
+
+type Stats struct {
+	MemorizedAyahs  int
+	QuranPercentage float64
+	AverageAccuracy *float64
+	StatusCounts    map[string]int
+	Juz             []Progress
+	Surahs          []Progress
+}
+
+// Progress is how much of one juz or surah has been memorized.
+type Progress struct {
+	Number         int
+	Name           string
+	MemorizedAyahs int
+	TotalAyahs     int
+	Percentage     float64
+}
+
+func percentage(part, whole int) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return math.Round(float64(part)/float64(whole)*10000) / 100
+}
+
+// GetStats aggregates a user's completed Memorize records. Overlapping
+// ranges are only counted once. Every juz is listed; only surahs with
+// memorized ayahs are.
+func (s *Service) GetStats(user model.User) (Stats, error) {
+	summary, err := s.repository.GetMemorizeSummary(user.ID)
+	if err != nil {
+		return Stats{}, err
+	}
+	spans, err := s.repository.GetMemorizedSpans(user.ID)
+	if err != nil {
+		return Stats{}, err
+	}
+
+	stats := Stats{
+		StatusCounts: map[string]int{
+			model.MemorizeStatusCompleted:  summary.Completed,
+			model.MemorizeStatusInProgress: summary.InProgress,
+			model.MemorizeStatusNotStarted: summary.NotStarted,
+		},
+		Juz:    []Progress{},
+		Surahs: []Progress{},
+	}
+	if summary.AverageAccuracy != nil {
+		average := math.Round(*summary.AverageAccuracy*100) / 100
+		stats.AverageAccuracy = &average
+	}
+
+	covered := func(whole quran.Span) int {
+		total := 0
+		for _, span := range spans {
+			total += span.Intersect(whole)
+		}
+		return total
+	}
+
+	for _, span := range spans {
+		stats.MemorizedAyahs += span.Len()
+	}
+	stats.QuranPercentage = percentage(stats.MemorizedAyahs, quran.TotalAyahs)
+
+	for number := 1; number <= quran.JuzCount; number++ {
+		juz, _ := quran.JuzSpan(number)
+		memorized := covered(juz)
+		stats.Juz = append(stats.Juz, Progress{
+			Number:         number,
+			Name:           fmt.Sprintf("Juz %d", number),
+			MemorizedAyahs: memorized,
+			TotalAyahs:     juz.Len(),
+			Percentage:     percentage(memorized, juz.Len()),
+		})
+	}
+
+	for _, surah := range quran.Surahs {
+		memorized := covered(surah.Span())
+		if memorized == 0 {
+			continue
+		}
+		stats.Surahs = append(stats.Surahs, Progress{
+			Number:         surah.Number,
+			Name:           surah.Name,
+			MemorizedAyahs: memorized,
+			TotalAyahs:     surah.AyahCount,
+			Percentage:     percentage(memorized, surah.AyahCount),
+		})
+	}
+	return stats, nil
+}
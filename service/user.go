@@ -0,0 +1,130 @@
+package service
+
+import (
+	"a21hc3NpZ25tZW50/model"
+	"a21hc3NpZ25tZW50/repository"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+var (
+	ErrInvalidRole        = validationError("role must be student or teacher")
+	ErrInvalidTimezone    = validationError("unknown timezone")
+	ErrUsernameTaken      = conflictError("username already registered")
+	ErrInvalidCredentials = errors.New("invalid username or password")
+)
+
+// UserInput is a registration request.
+type UserInput struct {
+	Username   string `binding:"required,username"`
+	Password   string `binding:"required,min=6,max=72"`
+	Fullname   string `binding:"max=100"`
+	Desc       string `binding:"max=500"`
+	ProfilePic string `binding:"omitempty,url,max=500"`
+	Timezone   string `binding:"omitempty,timezone"`
+}
+
+// UserProfile is what the API shows of a user. It never has the password.
+type UserProfile struct {
+	Username   string
+	Fullname   string
+	Desc       string
+	ProfilePic string
+	Role       string
+	Timezone   string
+}
+
+func profileOf(user model.User) UserProfile {
+	return UserProfile{
+		Username:   user.Username,
+		Fullname:   user.Fullname,
+		Desc:       user.Desc,
+		ProfilePic: user.ProfilePic,
+		Role:       user.Role,
+		Timezone:   user.Timezone,
+	}
+}
+
+func IsEmptyUser(user model.User) bool {
+	return reflect.DeepEqual(user, model.User{})
+}
+
+func (s *Service) Register(input UserInput) (UserProfile, error) {
+	user := model.User{
+		Username:   input.Username,
+		Password:   input.Password,
+		Fullname:   input.Fullname,
+		Desc:       input.Desc,
+		ProfilePic: input.ProfilePic,
+		Role:       model.RoleStudent,
+		Timezone:   input.Timezone,
+	}
+
+	userDB, err := s.repository.GetUserByUsername(user.Username)
+	if err != nil {
+		return UserProfile{}, err
+	}
+
+	if userDB.Username != "" && userDB.Username == user.Username {
+		return UserProfile{}, ErrUsernameTaken
+	}
+
+	if user.Timezone != "" {
+		if _, err := time.LoadLocation(user.Timezone); err != nil {
+			return UserProfile{}, ErrInvalidTimezone
+		}
+	}
+
+	if _, err := s.repository.AddUser(user); err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			return UserProfile{}, ErrUsernameTaken
+		}
+		return UserProfile{}, err
+	}
+	return profileOf(user), nil
+}
+
+// SetRole makes a user a student or a teacher. Users always register as
+// students, since a teacher can see the evaluations, recordings and comments
+// of the students in their groups; teachers are only made by an operator.
+func (s *Service) SetRole(username, role string) error {
+	if role != model.RoleStudent && role != model.RoleTeacher {
+		return ErrInvalidRole
+	}
+	if err := s.repository.SetUserRole(username, role); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return fmt.Errorf("%w: %s", ErrUserNotFound, username)
+		}
+		return err
+	}
+	return nil
+}
+
+// Login checks a user's password. Issuing a token or session is up to the
+// transport.
+func (s *Service) Login(username string, password string) (model.User, error) {
+	user, err := s.repository.GetUserByUsername(username)
+	if err != nil {
+		return model.User{}, err
+	}
+
+	if IsEmptyUser(user) || user.Password != password {
+		return model.User{}, ErrInvalidCredentials
+	}
+	return user, nil
+}
+
+// GetUser returns the user with the given username, such as the one a token
+// was issued to.
+func (s *Service) GetUser(username string) (model.User, error) {
+	user, err := s.repository.GetUserByUsername(username)
+	if err != nil {
+		return model.User{}, err
+	}
+	if user.ID == 0 {
+		return model.User{}, fmt.Errorf("%w: %s", ErrUserNotFound, username)
+	}
+	return user, nil
+}
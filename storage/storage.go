@@ -0,0 +1,21 @@
+package storage
+
+import (
+	"errors"
+	"io"
+)
+
+var (
+	ErrNotFound   = errors.New("file not found")
+	ErrInvalidKey = errors.New("invalid file key")
+)
+
+// FileStore keeps uploaded files under slash-separated keys such as
+// "recordings/12/3f9a.mp3".
+type FileStore interface {
+	Save(key string, content io.Reader) (int64, error)
+	// Open returns a seekable file so it can be served with HTTP range
+	// requests.
+	Open(key string) (io.ReadSeekCloser, error)
+	Delete(key string) error
+}